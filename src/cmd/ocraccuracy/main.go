@@ -0,0 +1,300 @@
+// Command ocraccuracy measures how well OCR reads the menu images of the corpus
+// and fails when the results are worse than the stored baseline, e.g.
+//
+//	go run ./cmd/ocraccuracy -corpus ../corpus/ocr
+//
+// Every corpus entry is a directory "<restaurant ID>/<YYYY-MM-DD>/" with the
+// recorded HTTP fixtures of a run (see MENUCKO_HTTP_RECORD_DIR), a hand-corrected
+// "transcript.txt" of the menu image and "expected.json" with the correct meals.
+// The restaurant parser runs against the fixtures with the production OCR, which
+// yields the character and word error rates of the text and the error rate of
+// the parsed meals. After an intended change, store the new results with
+// -update-baseline.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"menucko/config"
+	"menucko/restaurants"
+	"menucko/services/clock"
+	"menucko/services/httpclient"
+	"menucko/services/imageocr"
+	"menucko/services/pdftext"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const transcriptFile = "transcript.txt"
+const expectedFile = "expected.json"
+
+// Baseline holds the error rates of the corpus, lower is better.
+type Baseline struct {
+	CharErrorRate float64 `json:"charErrorRate"`
+	WordErrorRate float64 `json:"wordErrorRate"`
+	MealErrorRate float64 `json:"mealErrorRate"`
+}
+
+type totals struct {
+	charEdits, chars int
+	wordEdits, words int
+	mealEdits, meals int
+}
+
+func (t *totals) add(other totals) {
+	t.charEdits += other.charEdits
+	t.chars += other.chars
+	t.wordEdits += other.wordEdits
+	t.words += other.words
+	t.mealEdits += other.mealEdits
+	t.meals += other.meals
+}
+
+func (t totals) rates() Baseline {
+	return Baseline{
+		CharErrorRate: rate(t.charEdits, t.chars),
+		WordErrorRate: rate(t.wordEdits, t.words),
+		MealErrorRate: rate(t.mealEdits, t.meals),
+	}
+}
+
+func rate(edits, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+
+	return float64(edits) / float64(total)
+}
+
+// capturingImageOcr remembers the text read from the images, so the parser's own
+// OCR call is measured instead of running Tesseract twice.
+type capturingImageOcr struct {
+	imageocr.ImageOcr
+	texts *[]string
+}
+
+func (imageOcr capturingImageOcr) ParseImageText(ctx context.Context, imgBytes []byte, contentType string) (string, error) {
+	text, err := imageOcr.ImageOcr.ParseImageText(ctx, imgBytes, contentType)
+	if err == nil {
+		*imageOcr.texts = append(*imageOcr.texts, text)
+	}
+
+	return text, err
+}
+
+func (imageOcr capturingImageOcr) ParseImageLines(ctx context.Context, imgBytes []byte, contentType string) ([]imageocr.Line, error) {
+	lines, err := imageOcr.ImageOcr.ParseImageLines(ctx, imgBytes, contentType)
+	if err == nil {
+		*imageOcr.texts = append(*imageOcr.texts, imageocr.LinesText(lines))
+	}
+
+	return lines, err
+}
+
+func (imageOcr capturingImageOcr) WithPipeline(pipeline imageocr.Pipeline) imageocr.ImageOcr {
+	return capturingImageOcr{
+		ImageOcr: imageOcr.ImageOcr.WithPipeline(pipeline),
+		texts:    imageOcr.texts,
+	}
+}
+
+func main() {
+	corpusDir := flag.String("corpus", "../corpus/ocr", "directory with the OCR corpus")
+	configPath := flag.String("config", "../config/menucko.json", "config file with the restaurants")
+	baselinePath := flag.String("baseline", "", "baseline file, defaults to baseline.json in the corpus")
+	tolerance := flag.Float64("tolerance", 0.005, "allowed increase of an error rate over the baseline")
+	updateBaseline := flag.Bool("update-baseline", false, "store the measured error rates as the new baseline")
+	flag.Parse()
+
+	if *baselinePath == "" {
+		*baselinePath = filepath.Join(*corpusDir, "baseline.json")
+	}
+
+	measured, err := measureCorpus(*corpusDir, *configPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *updateBaseline {
+		if err = saveBaseline(*baselinePath, measured); err != nil {
+			log.Fatal(err)
+		}
+
+		fmt.Printf("\nBaseline stored in \"%s\"\n", *baselinePath)
+
+		return
+	}
+
+	baseline, err := loadBaseline(*baselinePath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if regressions := compare(baseline, measured, *tolerance); len(regressions) > 0 {
+		fmt.Printf("\nAccuracy dropped below the baseline:\n  %s\n", strings.Join(regressions, "\n  "))
+		os.Exit(1)
+	}
+
+	fmt.Println("\nAccuracy is at or above the baseline")
+}
+
+func measureCorpus(corpusDir, configPath string) (Baseline, error) {
+	conf, err := config.Load(configPath)
+	if err != nil {
+		return Baseline{}, err
+	}
+
+	transcripts, err := filepath.Glob(filepath.Join(corpusDir, "*", "*", transcriptFile))
+	if err != nil {
+		return Baseline{}, err
+	}
+
+	if len(transcripts) == 0 {
+		return Baseline{}, fmt.Errorf("corpus \"%s\" has no entries", corpusDir)
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "ENTRY\tCER\tWER\tMEAL ERRORS")
+
+	var corpusTotals totals
+
+	for _, transcript := range transcripts {
+		entryDir := filepath.Dir(transcript)
+		entry := filepath.Join(filepath.Base(filepath.Dir(entryDir)), filepath.Base(entryDir))
+
+		entryTotals, err := measureEntry(conf.Restaurants, entryDir)
+		if err != nil {
+			return Baseline{}, fmt.Errorf("corpus entry \"%s\": %w", entry, err)
+		}
+
+		rates := entryTotals.rates()
+		fmt.Fprintf(writer, "%s\t%.3f\t%.3f\t%.3f\n", entry, rates.CharErrorRate, rates.WordErrorRate, rates.MealErrorRate)
+
+		corpusTotals.add(entryTotals)
+	}
+
+	rates := corpusTotals.rates()
+	fmt.Fprintf(writer, "TOTAL\t%.3f\t%.3f\t%.3f\n", rates.CharErrorRate, rates.WordErrorRate, rates.MealErrorRate)
+
+	return rates, writer.Flush()
+}
+
+func measureEntry(configs []restaurants.Config, entryDir string) (totals, error) {
+	restaurantID := filepath.Base(filepath.Dir(entryDir))
+
+	var restaurantConfig *restaurants.Config
+
+	for index := range configs {
+		if configs[index].ID == restaurantID {
+			restaurantConfig = &configs[index]
+		}
+	}
+
+	if restaurantConfig == nil {
+		return totals{}, fmt.Errorf("restaurant \"%s\" isn't in the config", restaurantID)
+	}
+
+	date, err := time.Parse("2006-01-02", filepath.Base(entryDir))
+	if err != nil {
+		return totals{}, fmt.Errorf("directory name is not a date: %w", err)
+	}
+
+	transcript, err := os.ReadFile(filepath.Join(entryDir, transcriptFile))
+	if err != nil {
+		return totals{}, err
+	}
+
+	expectedContent, err := os.ReadFile(filepath.Join(entryDir, expectedFile))
+	if err != nil {
+		return totals{}, err
+	}
+
+	var expected []restaurants.Meal
+	if err = json.Unmarshal(expectedContent, &expected); err != nil {
+		return totals{}, err
+	}
+
+	httpClient, err := httpclient.NewReplayHTTPClient(entryDir)
+	if err != nil {
+		return totals{}, err
+	}
+
+	var texts []string
+
+	registry, err := restaurants.NewRegistry([]restaurants.Config{*restaurantConfig}, restaurants.Services{
+		Clock:      clock.DevClock{Frozen: date},
+		HTTPClient: httpClient,
+		ImageOcr:   capturingImageOcr{ImageOcr: imageocr.ProdImageOcr{}, texts: &texts},
+		PDFText:    pdftext.ProdPDFText{},
+	})
+	if err != nil {
+		return totals{}, err
+	}
+
+	menu, err := registry.Parsers()[0].Parse(context.Background())
+	if err != nil && len(texts) == 0 {
+		return totals{}, err
+	}
+
+	if len(texts) == 0 {
+		return totals{}, errors.New("the parser didn't read any image")
+	}
+
+	var entryTotals totals
+
+	entryTotals.charEdits, entryTotals.chars, entryTotals.wordEdits, entryTotals.words =
+		imageocr.TextErrors(string(transcript), strings.Join(texts, "\n"))
+
+	entryTotals.mealEdits, entryTotals.meals = mealErrors(expected, menu.Meals)
+
+	return entryTotals, nil
+}
+
+func compare(baseline, measured Baseline, tolerance float64) []string {
+	var regressions []string
+
+	check := func(name string, baselineRate, measuredRate float64) {
+		if measuredRate > baselineRate+tolerance {
+			regressions = append(regressions, fmt.Sprintf("%s %.3f, baseline %.3f", name, measuredRate, baselineRate))
+		}
+	}
+
+	check("character error rate", baseline.CharErrorRate, measured.CharErrorRate)
+	check("word error rate", baseline.WordErrorRate, measured.WordErrorRate)
+	check("meal error rate", baseline.MealErrorRate, measured.MealErrorRate)
+
+	return regressions
+}
+
+func loadBaseline(path string) (Baseline, error) {
+	var baseline Baseline
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return baseline, fmt.Errorf("baseline \"%s\" doesn't exist, create it with -update-baseline (make ocr-baseline)", path)
+	}
+
+	if err != nil {
+		return baseline, err
+	}
+
+	err = json.Unmarshal(content, &baseline)
+
+	return baseline, err
+}
+
+func saveBaseline(path string, baseline Baseline) error {
+	content, err := json.MarshalIndent(baseline, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(content, '\n'), 0o644)
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"menucko/restaurants"
+	"menucko/services/imageocr"
+)
+
+// mealFields flattens meals into comparable fields, each meal contributes its
+// name, price and dishes prefixed by the meal index.
+func mealFields(meals []restaurants.Meal) []string {
+	var fields []string
+
+	for _, meal := range meals {
+		fields = append(fields, "name:"+meal.Name, "price:"+meal.Price.String())
+
+		for _, dish := range meal.Dishes {
+			fields = append(fields, "dish:"+dish.Name)
+		}
+	}
+
+	return fields
+}
+
+// mealErrors counts the edits needed to turn the parsed meals into the expected
+// ones, field by field, and returns it with the number of expected fields.
+func mealErrors(expected, actual []restaurants.Meal) (int, int) {
+	expectedFields := mealFields(expected)
+
+	return imageocr.EditDistance(expectedFields, mealFields(actual)), len(expectedFields)
+}
@@ -0,0 +1,22 @@
+package main
+
+import (
+	"menucko/restaurants"
+	"testing"
+)
+
+func TestMealErrors(t *testing.T) {
+	expected := []restaurants.Meal{
+		{Name: "Menu 1", Price: restaurants.ParsePrice("6,90€"), Dishes: []restaurants.Dish{{Name: "Kuracie prsia"}, {Name: "ryža"}}},
+		{Name: "Menu 2", Price: restaurants.ParsePrice("7,40€"), Dishes: []restaurants.Dish{{Name: "Losos"}}},
+	}
+
+	actual := []restaurants.Meal{
+		{Name: "Menu 1", Price: restaurants.ParsePrice("6,90€"), Dishes: []restaurants.Dish{{Name: "Kuracie prsla"}, {Name: "ryža"}}},
+	}
+
+	edits, fields := mealErrors(expected, actual)
+	if edits != 4 || fields != 7 {
+		t.Errorf("unexpected errors %d/%d fields", edits, fields)
+	}
+}
@@ -0,0 +1,251 @@
+// Command ocreval runs image preprocessing pipelines against saved menu images
+// and reports which one Tesseract reads best, e.g.
+//
+//	go run ./cmd/ocreval -images "../corpus/ocr/*/*/*.jpg" -pipelines ../config/ocr-pipelines.json
+//
+// The pipelines file maps a pipeline name to its steps, in the same format as the
+// "ocr.preprocess" restaurant option. Pipelines are ranked by the word accuracy
+// against the hand-made "transcript.txt" beside the images, the text of all
+// images in a directory is compared with its transcript. Images without one only
+// count towards the mean Tesseract confidence, which breaks ties, as a pipeline
+// recognising fewer words with more confidence isn't any better.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"menucko/services/imageocr"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const transcriptFile = "transcript.txt"
+
+type result struct {
+	name     string
+	pipeline imageocr.Pipeline
+	// charAccuracy and wordAccuracy are one minus the error rates against the
+	// transcripts, they can be negative when the text has many extra words.
+	charAccuracy float64
+	wordAccuracy float64
+	transcripts  int
+	confidence   float64
+	words        int
+	duration     time.Duration
+	failures     int
+}
+
+func main() {
+	imagesPattern := flag.String("images", "", "glob pattern of the menu images")
+	pipelinesPath := flag.String("pipelines", "", "JSON file with the pipelines to compare")
+	verbose := flag.Bool("v", false, "print the recognised text of every image")
+	flag.Parse()
+
+	if err := run(*imagesPattern, *pipelinesPath, *verbose); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(imagesPattern, pipelinesPath string, verbose bool) error {
+	if imagesPattern == "" {
+		return errors.New("the -images flag is required")
+	}
+
+	imagePaths, err := filepath.Glob(imagesPattern)
+	if err != nil {
+		return err
+	}
+
+	if len(imagePaths) == 0 {
+		return fmt.Errorf("no images match \"%s\"", imagesPattern)
+	}
+
+	pipelines, err := loadPipelines(pipelinesPath)
+	if err != nil {
+		return err
+	}
+
+	results := make([]result, 0, len(pipelines))
+
+	for name, pipeline := range pipelines {
+		results = append(results, evaluate(name, pipeline, imagePaths, verbose))
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].wordAccuracy != results[j].wordAccuracy {
+			return results[i].wordAccuracy > results[j].wordAccuracy
+		}
+
+		if results[i].charAccuracy != results[j].charAccuracy {
+			return results[i].charAccuracy > results[j].charAccuracy
+		}
+
+		if results[i].confidence != results[j].confidence {
+			return results[i].confidence > results[j].confidence
+		}
+
+		return results[i].name < results[j].name
+	})
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintln(writer, "PIPELINE\tWORD ACC\tCHAR ACC\tCONFIDENCE\tWORDS\tFAILURES\tTIME\tSTEPS")
+
+	for _, r := range results {
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%.1f\t%d\t%d\t%s\t%s\n",
+			r.name, formatAccuracy(r.wordAccuracy, r.transcripts), formatAccuracy(r.charAccuracy, r.transcripts),
+			r.confidence, r.words, r.failures, r.duration.Round(time.Millisecond), r.pipeline)
+	}
+
+	if err = writer.Flush(); err != nil {
+		return err
+	}
+
+	if results[0].transcripts == 0 {
+		fmt.Printf("\nNo image has a \"%s\", pipelines are ranked by confidence only\n", transcriptFile)
+	}
+
+	fmt.Printf("\nBest pipeline for %d images: %s\n", len(imagePaths), results[0].name)
+
+	return nil
+}
+
+// loadPipelines reads the pipelines file. The default pipeline is always part of
+// the comparison, so there's a baseline to beat.
+func loadPipelines(path string) (map[string]imageocr.Pipeline, error) {
+	pipelines := make(map[string]imageocr.Pipeline)
+
+	if path != "" {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		if err = json.Unmarshal(content, &pipelines); err != nil {
+			return nil, fmt.Errorf("invalid pipelines file \"%s\": %w", path, err)
+		}
+	}
+
+	for name, pipeline := range pipelines {
+		if err := pipeline.Validate(); err != nil {
+			return nil, fmt.Errorf("pipeline \"%s\": %w", name, err)
+		}
+	}
+
+	if _, ok := pipelines["default"]; !ok {
+		pipelines["default"] = imageocr.DefaultPipeline
+	}
+
+	return pipelines, nil
+}
+
+func formatAccuracy(accuracy float64, transcripts int) string {
+	if transcripts == 0 {
+		return "-"
+	}
+
+	return fmt.Sprintf("%.1f %%", 100*accuracy)
+}
+
+func evaluate(name string, pipeline imageocr.Pipeline, imagePaths []string, verbose bool) result {
+	r := result{
+		name:     name,
+		pipeline: pipeline,
+	}
+
+	imageOcr := imageocr.ProdImageOcr{}.WithPipeline(pipeline)
+
+	var confidenceSum, weightSum float64
+
+	// The text of the images in each directory, failed images add no text, so they
+	// count as missing words against the transcript.
+	dirTexts := make(map[string][]string)
+	var dirs []string
+
+	start := time.Now()
+
+	for _, imagePath := range imagePaths {
+		dir := filepath.Dir(imagePath)
+
+		if _, ok := dirTexts[dir]; !ok {
+			dirTexts[dir] = nil
+			dirs = append(dirs, dir)
+		}
+
+		imgBytes, err := os.ReadFile(imagePath)
+		if err != nil {
+			log.Printf("[OCR Eval] Reading \"%s\" failed: %v", imagePath, err)
+			r.failures++
+
+			continue
+		}
+
+		lines, err := imageOcr.ParseImageLines(context.Background(), imgBytes, "")
+		if err != nil {
+			log.Printf("[OCR Eval] Pipeline \"%s\" failed on \"%s\": %v", name, imagePath, err)
+			r.failures++
+
+			continue
+		}
+
+		for _, line := range lines {
+			weight := float64(len([]rune(line.Text())))
+
+			confidenceSum += line.Confidence() * weight
+			weightSum += weight
+			r.words += len(line.Words)
+		}
+
+		dirTexts[dir] = append(dirTexts[dir], imageocr.LinesText(lines))
+
+		if verbose {
+			fmt.Printf("=== %s: %s\n%s\n\n", name, imagePath, strings.TrimSpace(imageocr.LinesText(lines)))
+		}
+	}
+
+	r.duration = time.Since(start)
+
+	if weightSum > 0 {
+		r.confidence = confidenceSum / weightSum
+	}
+
+	var charEdits, chars, wordEdits, words int
+
+	for _, dir := range dirs {
+		transcript, err := os.ReadFile(filepath.Join(dir, transcriptFile))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+
+		if err != nil {
+			log.Printf("[OCR Eval] Reading the transcript of \"%s\" failed: %v", dir, err)
+			continue
+		}
+
+		dirCharEdits, dirChars, dirWordEdits, dirWords := imageocr.TextErrors(string(transcript), strings.Join(dirTexts[dir], "\n"))
+
+		charEdits += dirCharEdits
+		chars += dirChars
+		wordEdits += dirWordEdits
+		words += dirWords
+		r.transcripts++
+	}
+
+	if chars > 0 {
+		r.charAccuracy = 1 - float64(charEdits)/float64(chars)
+	}
+
+	if words > 0 {
+		r.wordAccuracy = 1 - float64(wordEdits)/float64(words)
+	}
+
+	return r
+}
@@ -0,0 +1,260 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"menucko/restaurants"
+	"menucko/services/httpclient"
+	"os"
+	"strings"
+	"time"
+)
+
+const htmlTemplateEnv = "MENUCKO_HTML_TEMPLATE"
+const stylesPathEnv = "MENUCKO_STYLES_PATH"
+const commitHashEnv = "MENUCKO_COMMIT_HASH"
+const blobConnStrEnv = "MENUCKO_BLOB_CONN_STR"
+const blobContNameEnv = "MENUCKO_BLOB_CONT_NAME"
+const blobNameEnv = "MENUCKO_BLOB_NAME"
+const tomorrowBlobNameEnv = "MENUCKO_TOMORROW_BLOB_NAME"
+const weekBlobNameEnv = "MENUCKO_WEEK_BLOB_NAME"
+const runTimeoutEnv = "MENUCKO_RUN_TIMEOUT"
+const restaurantTimeoutEnv = "MENUCKO_RESTAURANT_TIMEOUT"
+const httpCacheDirEnv = "MENUCKO_HTTP_CACHE_DIR"
+const httpOfflineEnv = "MENUCKO_HTTP_OFFLINE"
+const httpRecordDirEnv = "MENUCKO_HTTP_RECORD_DIR"
+const httpReplayDirEnv = "MENUCKO_HTTP_REPLAY_DIR"
+const resultCacheDirEnv = "MENUCKO_RESULT_CACHE_DIR"
+const timeZoneEnv = "MENUCKO_TIME_ZONE"
+
+// Restaurant URLs can be overridden with "MENUCKO_<ID>_URL", e.g. "MENUCKO_PIZZA_URL".
+const restaurantURLEnvFormat = "MENUCKO_%s_URL"
+
+const defaultTimeZone = "Europe/Bratislava"
+const defaultTomorrowBlobName = "tomorrow.html"
+const defaultWeekBlobName = "week.html"
+
+const LocalDistributor = "local"
+const AzureDistributor = "azure"
+
+type Config struct {
+	Restaurants []restaurants.Config `json:"restaurants"`
+	Clock       Clock                `json:"clock"`
+	Runner      Runner               `json:"runner"`
+	HTTP        HTTP                 `json:"http"`
+	ResultCache ResultCache          `json:"resultCache"`
+	Renderer    Renderer             `json:"renderer"`
+	Distributor Distributor          `json:"distributor"`
+}
+
+// Clock sets the time zone in which the days of the menus start and end.
+type Clock struct {
+	TimeZone string `json:"timeZone"`
+}
+
+type Runner struct {
+	Timeout           string `json:"timeout"`
+	RestaurantTimeout string `json:"restaurantTimeout"`
+}
+
+type HTTP struct {
+	Timeout     string `json:"timeout"`
+	MaxBodySize int64  `json:"maxBodySize"`
+	MaxRetries  *int   `json:"maxRetries"`
+	Backoff     string `json:"backoff"`
+	MaxBackoff  string `json:"maxBackoff"`
+	Cache       Cache  `json:"cache"`
+	// RecordDir saves all responses of a run as fixtures, ReplayDir serves them back
+	// instead of making any request.
+	RecordDir string `json:"recordDir"`
+	ReplayDir string `json:"replayDir"`
+}
+
+// Cache stores HTTP responses in Dir, the cache is disabled when Dir is empty.
+type Cache struct {
+	Dir     string `json:"dir"`
+	TTL     string `json:"ttl"`
+	Offline bool   `json:"offline"`
+}
+
+// ResultCache stores OCR and PDF text extraction results in Dir, the cache is
+// disabled when Dir is empty.
+type ResultCache struct {
+	Dir     string `json:"dir"`
+	MaxAge  string `json:"maxAge"`
+	MaxSize int64  `json:"maxSize"`
+}
+
+type Renderer struct {
+	TemplatePath string `json:"templatePath"`
+	StylesPath   string `json:"stylesPath"`
+	CommitHash   string `json:"commitHash"`
+}
+
+type Distributor struct {
+	Type             string `json:"type"`
+	ConnectionString string `json:"connectionString"`
+	Container        string `json:"container"`
+	// BlobName is the page with today's menus, the pages with tomorrow's menus
+	// and the week overview default to "tomorrow.html" and "week.html".
+	BlobName         string `json:"blobName"`
+	TomorrowBlobName string `json:"tomorrowBlobName"`
+	WeekBlobName     string `json:"weekBlobName"`
+}
+
+func Load(path string) (Config, error) {
+	var config Config
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return config, err
+	}
+
+	if err = json.Unmarshal(content, &config); err != nil {
+		return config, fmt.Errorf("config file \"%s\" is not valid JSON: %w", path, err)
+	}
+
+	config.applyEnv()
+	config.applyDefaults()
+
+	return config, nil
+}
+
+func (config *Config) applyEnv() {
+	overrideFromEnv(&config.Clock.TimeZone, timeZoneEnv)
+	overrideFromEnv(&config.Runner.Timeout, runTimeoutEnv)
+	overrideFromEnv(&config.Runner.RestaurantTimeout, restaurantTimeoutEnv)
+	overrideFromEnv(&config.HTTP.Cache.Dir, httpCacheDirEnv)
+	overrideFromEnv(&config.HTTP.RecordDir, httpRecordDirEnv)
+	overrideFromEnv(&config.HTTP.ReplayDir, httpReplayDirEnv)
+	overrideFromEnv(&config.ResultCache.Dir, resultCacheDirEnv)
+
+	if offline := os.Getenv(httpOfflineEnv); len(offline) != 0 {
+		config.HTTP.Cache.Offline = offline != "0" && offline != "false"
+	}
+
+	overrideFromEnv(&config.Renderer.TemplatePath, htmlTemplateEnv)
+	overrideFromEnv(&config.Renderer.StylesPath, stylesPathEnv)
+	overrideFromEnv(&config.Renderer.CommitHash, commitHashEnv)
+
+	if blobConnStr := os.Getenv(blobConnStrEnv); len(blobConnStr) != 0 {
+		if blobConnStr == LocalDistributor {
+			config.Distributor.Type = LocalDistributor
+		} else {
+			config.Distributor.Type = AzureDistributor
+			config.Distributor.ConnectionString = blobConnStr
+		}
+	}
+
+	overrideFromEnv(&config.Distributor.Container, blobContNameEnv)
+	overrideFromEnv(&config.Distributor.BlobName, blobNameEnv)
+	overrideFromEnv(&config.Distributor.TomorrowBlobName, tomorrowBlobNameEnv)
+	overrideFromEnv(&config.Distributor.WeekBlobName, weekBlobNameEnv)
+
+	for index := range config.Restaurants {
+		restaurant := &config.Restaurants[index]
+
+		env := fmt.Sprintf(restaurantURLEnvFormat, strings.ToUpper(restaurant.ID))
+		overrideFromEnv(&restaurant.URL, env)
+	}
+}
+
+func (config *Config) applyDefaults() {
+	if len(config.Clock.TimeZone) == 0 {
+		config.Clock.TimeZone = defaultTimeZone
+	}
+
+	if len(config.Distributor.TomorrowBlobName) == 0 {
+		config.Distributor.TomorrowBlobName = defaultTomorrowBlobName
+	}
+
+	if len(config.Distributor.WeekBlobName) == 0 {
+		config.Distributor.WeekBlobName = defaultWeekBlobName
+	}
+}
+
+func overrideFromEnv(value *string, env string) {
+	if envValue := os.Getenv(env); len(envValue) != 0 {
+		*value = envValue
+	}
+}
+
+func (clock Clock) Location() (*time.Location, error) {
+	location, err := time.LoadLocation(clock.TimeZone)
+	if err != nil {
+		return nil, fmt.Errorf("config key \"clock.timeZone\" with value \"%s\" is not a valid time zone: %w", clock.TimeZone, err)
+	}
+
+	return location, nil
+}
+
+func (runner Runner) Timeouts() (time.Duration, time.Duration, error) {
+	timeout, err := parseDuration("runner.timeout", runner.Timeout)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	restaurantTimeout, err := parseDuration("runner.restaurantTimeout", runner.RestaurantTimeout)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return timeout, restaurantTimeout, nil
+}
+
+func parseDuration(key string, value string) (time.Duration, error) {
+	if len(value) == 0 {
+		return 0, nil
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("config key \"%s\" with value \"%s\" is not a valid duration", key, value)
+	}
+
+	return duration, nil
+}
+
+// ClientConfig returns the HTTP client config, keys missing in the config file
+// fall back to httpclient.DefaultConfig.
+func (conf HTTP) ClientConfig() (httpclient.Config, error) {
+	clientConfig := httpclient.DefaultConfig
+
+	var err error
+
+	if len(conf.Timeout) != 0 {
+		if clientConfig.Timeout, err = parseDuration("http.timeout", conf.Timeout); err != nil {
+			return clientConfig, err
+		}
+	}
+
+	if len(conf.Backoff) != 0 {
+		if clientConfig.Backoff, err = parseDuration("http.backoff", conf.Backoff); err != nil {
+			return clientConfig, err
+		}
+	}
+
+	if len(conf.MaxBackoff) != 0 {
+		if clientConfig.MaxBackoff, err = parseDuration("http.maxBackoff", conf.MaxBackoff); err != nil {
+			return clientConfig, err
+		}
+	}
+
+	if conf.MaxBodySize > 0 {
+		clientConfig.MaxBodySize = conf.MaxBodySize
+	}
+
+	if conf.MaxRetries != nil {
+		clientConfig.MaxRetries = *conf.MaxRetries
+	}
+
+	return clientConfig, nil
+}
+
+func (conf Cache) TTLDuration() (time.Duration, error) {
+	return parseDuration("http.cache.ttl", conf.TTL)
+}
+
+func (conf ResultCache) MaxAgeDuration() (time.Duration, error) {
+	return parseDuration("resultCache.maxAge", conf.MaxAge)
+}
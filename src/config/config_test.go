@@ -0,0 +1,135 @@
+package config
+
+import (
+	"menucko/services/httpclient"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const testConfig = `{
+	"restaurants": [{"id": "pizza", "type": "html", "url": "https://www.pizza-pizza.sk/"}],
+	"runner": {"timeout": "1m"},
+	"distributor": {"type": "azure", "connectionString": "from-file", "container": "menu", "blobName": "index.html"}
+}`
+
+func loadTestConfig(t *testing.T) Config {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "menucko.json")
+
+	if err := os.WriteFile(path, []byte(testConfig), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return config
+}
+
+func TestLoadEnv(t *testing.T) {
+	tests := []struct {
+		name  string
+		env   map[string]string
+		check func(config Config) bool
+	}{
+		{
+			"file values without env",
+			nil,
+			func(config Config) bool {
+				return config.Runner.Timeout == "1m" && config.Distributor.Type == AzureDistributor &&
+					config.Distributor.ConnectionString == "from-file" &&
+					config.Restaurants[0].URL == "https://www.pizza-pizza.sk/"
+			},
+		},
+		{
+			"defaults",
+			nil,
+			func(config Config) bool {
+				return config.Clock.TimeZone == defaultTimeZone &&
+					config.Distributor.TomorrowBlobName == defaultTomorrowBlobName &&
+					config.Distributor.WeekBlobName == defaultWeekBlobName
+			},
+		},
+		{
+			"local blob connection string",
+			map[string]string{blobConnStrEnv: "local"},
+			func(config Config) bool {
+				return config.Distributor.Type == LocalDistributor && config.Distributor.ConnectionString == "from-file"
+			},
+		},
+		{
+			"azure blob connection string",
+			map[string]string{blobConnStrEnv: "from-env"},
+			func(config Config) bool {
+				return config.Distributor.Type == AzureDistributor && config.Distributor.ConnectionString == "from-env"
+			},
+		},
+		{
+			"restaurant URL",
+			map[string]string{"MENUCKO_PIZZA_URL": "http://localhost:8080/pizza"},
+			func(config Config) bool {
+				return config.Restaurants[0].URL == "http://localhost:8080/pizza"
+			},
+		},
+		{
+			"strings override the file and the defaults",
+			map[string]string{runTimeoutEnv: "2m", timeZoneEnv: "UTC", blobNameEnv: "today.html", weekBlobNameEnv: "tyzden.html"},
+			func(config Config) bool {
+				return config.Runner.Timeout == "2m" && config.Clock.TimeZone == "UTC" &&
+					config.Distributor.BlobName == "today.html" && config.Distributor.WeekBlobName == "tyzden.html"
+			},
+		},
+		{
+			"offline",
+			map[string]string{httpOfflineEnv: "1"},
+			func(config Config) bool {
+				return config.HTTP.Cache.Offline
+			},
+		},
+		{
+			"offline disabled",
+			map[string]string{httpOfflineEnv: "false"},
+			func(config Config) bool {
+				return !config.HTTP.Cache.Offline
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for env, value := range test.env {
+				t.Setenv(env, value)
+			}
+
+			if config := loadTestConfig(t); !test.check(config) {
+				t.Errorf("unexpected config %+v", config)
+			}
+		})
+	}
+}
+
+func TestClientConfig(t *testing.T) {
+	maxRetries := 0
+
+	clientConfig, err := HTTP{Timeout: "5s", MaxRetries: &maxRetries}.ClientConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := httpclient.DefaultConfig
+	want.Timeout = 5 * time.Second
+	want.MaxRetries = 0
+
+	if clientConfig != want {
+		t.Errorf("ClientConfig() = %+v, want %+v", clientConfig, want)
+	}
+
+	if _, err = (HTTP{Backoff: "soon"}).ClientConfig(); err == nil {
+		t.Error("expected invalid backoff to fail")
+	}
+}
@@ -8,7 +8,9 @@ require (
 	github.com/ericchiang/css v1.3.0
 	github.com/otiai10/gosseract/v2 v2.4.1
 	github.com/tdewolff/minify/v2 v2.20.18
+	golang.org/x/image v0.15.0
 	golang.org/x/net v0.24.0
+	golang.org/x/text v0.14.0
 )
 
 require (
@@ -16,6 +18,4 @@ require (
 	github.com/Azure/azure-sdk-for-go/sdk/internal v1.5.2 // indirect
 	github.com/stretchr/testify v1.9.0 // indirect
 	github.com/tdewolff/parse/v2 v2.7.12 // indirect
-	golang.org/x/image v0.15.0 // indirect
-	golang.org/x/text v0.14.0 // indirect
 )
@@ -1,69 +1,85 @@
 package main
 
 import (
+	"context"
 	"log"
 	"menucko/restaurants"
-	"menucko/services/httpclient"
-	"menucko/services/imageocr"
-	"sync"
+	"menucko/services/renderer"
 )
 
 func main() {
-	dateResolver, err := getDateResolver()
+	conf, err := getConfig()
 	if err != nil {
 		log.Println(err)
 		return
 	}
 
-	httpClient := httpclient.ProdHTTPClient{}
-	imageOcr := imageocr.ProdImageOcr{}
-
-	rend, err := getRenderer()
+	clock, err := getClock(conf)
 	if err != nil {
 		log.Println(err)
 		return
 	}
 
-	dist, err := getDistributor()
+	httpClient, err := getHTTPClient(conf)
 	if err != nil {
 		log.Println(err)
 		return
 	}
 
-	menuChan := make(chan restaurants.Menu, 4)
-
-	waitGroup := sync.WaitGroup{}
-
-	waitGroup.Add(1)
-	go restaurants.ParsePizza(menuChan, &waitGroup, dateResolver, httpClient)
-
-	waitGroup.Add(1)
-	go restaurants.ParseKozel(menuChan, &waitGroup, dateResolver, httpClient)
-
-	waitGroup.Add(1)
-	go restaurants.ParseLindy(menuChan, &waitGroup, httpClient, imageOcr)
-
-	waitGroup.Add(1)
-	go restaurants.ParseErika(menuChan, &waitGroup, httpClient)
+	resultCache, err := getResultCache(conf)
+	if err != nil {
+		log.Println(err)
+		return
+	}
 
-	waitGroup.Wait()
+	services := restaurants.Services{
+		Clock:      clock,
+		HTTPClient: httpClient,
+		ImageOcr:   getImageOcr(resultCache),
+		PDFText:    getPDFText(resultCache),
+	}
 
-	close(menuChan)
+	registry, err := getRegistry(conf, services)
+	if err != nil {
+		log.Println(err)
+		return
+	}
 
-	var menus [4]restaurants.Menu
-	for menu := range menuChan {
-		menus[menu.Restaurant] = menu
+	runner, err := getRunner(conf, registry, clock)
+	if err != nil {
+		log.Println(err)
+		return
 	}
 
-	menusSlice := menus[:]
+	fileNames, err := getFileNames(conf)
+	if err != nil {
+		log.Println(err)
+		return
+	}
 
-	htmlContent, err := rend.RenderMenus(&menusSlice)
+	rend, err := getRenderer(conf, clock, fileNames)
 	if err != nil {
-		htmlContent = rend.GetErrorContent()
+		log.Println(err)
+		return
 	}
 
-	err = dist.Distribute(htmlContent)
+	dist, err := getDistributor(conf)
 	if err != nil {
 		log.Println(err)
+		return
+	}
+
+	weeks := runner.Run(context.Background())
+
+	for _, page := range renderer.Pages {
+		htmlContent, err := rend.RenderMenus(&weeks, page)
+		if err != nil {
+			htmlContent = rend.GetErrorContent()
+		}
+
+		err = dist.Distribute(fileNames[page], htmlContent)
+		if err != nil {
+			log.Println(err)
+		}
 	}
 }
@@ -0,0 +1,126 @@
+package restaurants
+
+import (
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Allergen is one of the 14 allergens restaurants in the EU have to declare, the
+// value is its number from Annex II of Regulation (EU) No 1169/2011.
+type Allergen int
+
+const (
+	AllergenGluten Allergen = iota + 1
+	AllergenCrustaceans
+	AllergenEggs
+	AllergenFish
+	AllergenPeanuts
+	AllergenSoybeans
+	AllergenMilk
+	AllergenNuts
+	AllergenCelery
+	AllergenMustard
+	AllergenSesame
+	AllergenSulphites
+	AllergenLupin
+	AllergenMolluscs
+)
+
+// Allergens lists all allergens in the order of their numbers, e.g. for a legend.
+var Allergens = []Allergen{
+	AllergenGluten, AllergenCrustaceans, AllergenEggs, AllergenFish, AllergenPeanuts,
+	AllergenSoybeans, AllergenMilk, AllergenNuts, AllergenCelery, AllergenMustard,
+	AllergenSesame, AllergenSulphites, AllergenLupin, AllergenMolluscs,
+}
+
+var allergenNames = map[Allergen]string{
+	AllergenGluten:      "Obilniny obsahujúce lepok",
+	AllergenCrustaceans: "Kôrovce",
+	AllergenEggs:        "Vajcia",
+	AllergenFish:        "Ryby",
+	AllergenPeanuts:     "Arašidy",
+	AllergenSoybeans:    "Sója",
+	AllergenMilk:        "Mlieko",
+	AllergenNuts:        "Orechy",
+	AllergenCelery:      "Zeler",
+	AllergenMustard:     "Horčica",
+	AllergenSesame:      "Sezam",
+	AllergenSulphites:   "Oxid siričitý a siričitany",
+	AllergenLupin:       "Vlčí bôb",
+	AllergenMolluscs:    "Mäkkýše",
+}
+
+// Name returns the Slovak name used on menus.
+func (allergen Allergen) Name() string {
+	return allergenNames[allergen]
+}
+
+func (allergen Allergen) valid() bool {
+	return allergen >= AllergenGluten && allergen <= AllergenMolluscs
+}
+
+// Menus list allergens as numbers after the dish, e.g. "Rezeň 1,3,7", "Rezeň (1, 3, 7)",
+// "Rezeň A: 1.3.7" or "Rezeň /1,3,7/". The numbers may also be in brackets
+// in the middle of the text.
+var (
+	allergenListPattern    = `\d{1,2}(?:\s*[,.;]\s*\d{1,2})*`
+	trailingAllergensRe    = regexp.MustCompile(`(?i)\s+(?:(?:a|alerg[eé]ny)\s*:?\s*)?[(/\[]?(` + allergenListPattern + `)[)/\]]?[.,]?$`)
+	bracketedAllergensRe   = regexp.MustCompile(`(?i)\s*[(\[](?:(?:a|alerg[eé]ny)\s*:?\s*)?(` + allergenListPattern + `)[)\]]`)
+	allergenNumberSplitter = regexp.MustCompile(`\D+`)
+	duplicateWhitespaceRe  = regexp.MustCompile(`\s+`)
+)
+
+// extractAllergens removes the allergen numbers from the dish text and returns
+// them sorted. Numbers outside of 1–14 aren't allergens, e.g. a weight in grams,
+// so such a list is left in the text.
+func extractAllergens(text string) (string, []Allergen) {
+	var allergens []Allergen
+
+	extract := func(re *regexp.Regexp) {
+		text = re.ReplaceAllStringFunc(text, func(match string) string {
+			parsed, ok := parseAllergenList(re.FindStringSubmatch(match)[1])
+			if !ok {
+				return match
+			}
+
+			allergens = append(allergens, parsed...)
+
+			return " "
+		})
+	}
+
+	extract(trailingAllergensRe)
+	extract(bracketedAllergensRe)
+
+	text = strings.TrimSpace(duplicateWhitespaceRe.ReplaceAllString(text, " "))
+
+	return text, normalizeAllergens(allergens)
+}
+
+func parseAllergenList(list string) ([]Allergen, bool) {
+	var allergens []Allergen
+
+	for _, number := range allergenNumberSplitter.Split(list, -1) {
+		value, err := strconv.Atoi(number)
+		if err != nil || !Allergen(value).valid() {
+			return nil, false
+		}
+
+		allergens = append(allergens, Allergen(value))
+	}
+
+	return allergens, len(allergens) > 0
+}
+
+// normalizeAllergens sorts the allergens and removes duplicates.
+func normalizeAllergens(allergens []Allergen) []Allergen {
+	if len(allergens) == 0 {
+		return nil
+	}
+
+	slices.Sort(allergens)
+
+	return slices.Compact(allergens)
+}
@@ -0,0 +1,44 @@
+package restaurants
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestExtractAllergens(t *testing.T) {
+	tests := []struct {
+		text      string
+		name      string
+		allergens []Allergen
+	}{
+		{"Kuracie prsia na grile 1,7", "Kuracie prsia na grile", []Allergen{1, 7}},
+		{"Bravčová krkovička 7, 3, 1", "Bravčová krkovička", []Allergen{1, 3, 7}},
+		{"Rezeň (1,3,7)", "Rezeň", []Allergen{1, 3, 7}},
+		{"Rezeň A: 1.3.7", "Rezeň", []Allergen{1, 3, 7}},
+		{"Rezeň alergény: 1;3", "Rezeň", []Allergen{1, 3}},
+		{"Rezeň /1,3,7/", "Rezeň", []Allergen{1, 3, 7}},
+		{"Rezeň (1,3) so šalátom (7, 10)", "Rezeň so šalátom", []Allergen{1, 3, 7, 10}},
+		{"Losos 4", "Losos", []Allergen{4}},
+		{"Hovädzí vývar 0,33l", "Hovädzí vývar 0,33l", nil},
+		{"Pizza Margherita 32cm", "Pizza Margherita 32cm", nil},
+		{"Rezeň 150", "Rezeň 150", nil},
+		{"Rezeň 1,3,15", "Rezeň 1,3,15", nil},
+		{"Dezert dňa", "Dezert dňa", nil},
+	}
+
+	for _, test := range tests {
+		name, allergens := extractAllergens(test.text)
+
+		if name != test.name || !slices.Equal(allergens, test.allergens) {
+			t.Errorf("extractAllergens(\"%s\") = \"%s\", %v, want \"%s\", %v", test.text, name, allergens, test.name, test.allergens)
+		}
+	}
+}
+
+func TestMealAllergens(t *testing.T) {
+	meal := Meal{Dishes: []Dish{newDish("Polievka 1,9"), newDish("Rezeň 1,3,7"), newDish("Šalát")}}
+
+	if allergens := meal.Allergens(); !slices.Equal(allergens, []Allergen{1, 3, 7, 9}) {
+		t.Errorf("unexpected allergens %v", allergens)
+	}
+}
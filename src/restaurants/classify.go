@@ -0,0 +1,242 @@
+package restaurants
+
+import (
+	_ "embed"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+type Course string
+
+const (
+	CourseSoup    Course = "soup"
+	CourseMain    Course = "main"
+	CourseDessert Course = "dessert"
+	CourseSalad   Course = "salad"
+	CourseDrink   Course = "drink"
+)
+
+type Diet string
+
+const (
+	DietVegetarian Diet = "vegetarian"
+	DietVegan      Diet = "vegan"
+	DietGlutenFree Diet = "gluten-free"
+)
+
+var courseNames = map[Course]string{
+	CourseSoup:    "Polievka",
+	CourseMain:    "Hlavné jedlo",
+	CourseDessert: "Dezert",
+	CourseSalad:   "Šalát",
+	CourseDrink:   "Nápoj",
+}
+
+var dietNames = map[Diet]string{
+	DietVegetarian: "Vegetariánske",
+	DietVegan:      "Vegánske",
+	DietGlutenFree: "Bezlepkové",
+}
+
+// Name returns the Slovak name of the course.
+func (course Course) Name() string {
+	return courseNames[course]
+}
+
+// Name returns the Slovak label of the diet.
+func (diet Diet) Name() string {
+	return dietNames[diet]
+}
+
+func parseCourse(value string) (Course, error) {
+	course := Course(value)
+
+	if len(value) != 0 && len(course.Name()) == 0 {
+		return "", fmt.Errorf("course \"%s\" is unknown", value)
+	}
+
+	return course, nil
+}
+
+// A meal with dishes of several courses, e.g. a soup and a main, is of the course
+// with the lowest rank.
+var courseRanks = map[Course]int{
+	CourseMain:    0,
+	CourseSalad:   1,
+	CourseDessert: 2,
+	CourseSoup:    3,
+	CourseDrink:   4,
+}
+
+var keywordCourses = []Course{CourseSoup, CourseSalad, CourseDessert, CourseDrink}
+
+const (
+	keywordsMeat     = "meat"
+	keywordsMeatless = "meatless"
+)
+
+//go:embed classification.txt
+var classificationContent string
+
+var classificationKeywords = loadKeywords(classificationContent)
+
+// loadKeywords reads the "[section]" headers and the keywords below them.
+func loadKeywords(content string) map[string][]string {
+	keywords := make(map[string][]string)
+
+	var section string
+
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = line[1 : len(line)-1]
+			continue
+		}
+
+		keywords[section] = append(keywords[section], strings.ToLower(line))
+	}
+
+	return keywords
+}
+
+// sideConjunctions join the sides to the dish, e.g. "Kuracie prsia s ryžou a šalátom".
+var sideConjunctions = []string{"s", "so", "a", "na"}
+
+func splitWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool { return !unicode.IsLetter(r) })
+}
+
+func hasKeyword(text string, section string) bool {
+	return matchesKeyword(splitWords(text), section)
+}
+
+func matchesKeyword(words []string, section string) bool {
+	for _, word := range words {
+		for _, keyword := range classificationKeywords[section] {
+			if strings.HasPrefix(word, keyword) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// dishHead returns the words naming the dish itself without its sides, which follow
+// the first comma or a conjunction, e.g. "Kurací rezeň" of "Kurací rezeň, krémový
+// špenát" or "Bravčové" of "Bravčové na krémovej omáčke s knedľou".
+func dishHead(text string) []string {
+	text, _, _ = strings.Cut(text, ",")
+
+	words := splitWords(text)
+
+	for index, word := range words {
+		if index > 0 && slices.Contains(sideConjunctions, word) {
+			return words[:index]
+		}
+	}
+
+	return words
+}
+
+// keywordCourse returns the course of the text, or an empty course when no keyword
+// matches. Only the dish itself decides, a side like "s ryžou a šalátom" doesn't.
+func keywordCourse(text string) Course {
+	words := dishHead(text)
+	if len(words) == 0 {
+		return ""
+	}
+
+	for _, course := range keywordCourses {
+		if matchesKeyword(words, string(course)) || matchesKeyword(words[len(words)-1:], string(course)+"-last") {
+			return course
+		}
+	}
+
+	return ""
+}
+
+// classifyMeals fills in the course of meals the source didn't structure and the
+// diets of all meals.
+func classifyMeals(meals []Meal) {
+	for index := range meals {
+		meal := &meals[index]
+
+		if len(meal.Course) == 0 {
+			meal.Course = mealCourse(*meal)
+		}
+
+		meal.Diets = mealDiets(*meal)
+	}
+}
+
+// mealCourse prefers a course in the meal name, e.g. "Polievka dňa", and otherwise
+// picks the most substantial course of its dishes. Dishes without a keyword are mains.
+func mealCourse(meal Meal) Course {
+	if course := keywordCourse(meal.Name); len(course) != 0 {
+		return course
+	}
+
+	var course Course
+
+	for _, dish := range meal.Dishes {
+		dishCourse := keywordCourse(dish.Name)
+		if len(dishCourse) == 0 {
+			dishCourse = CourseMain
+		}
+
+		if len(course) == 0 || courseRanks[dishCourse] < courseRanks[course] {
+			course = dishCourse
+		}
+	}
+
+	if len(course) == 0 {
+		return CourseMain
+	}
+
+	return course
+}
+
+// mealDiets sets a diet only on evidence, as people choose meals by it. A label on
+// the menu decides, and a meat substitute like tofu makes a meal vegetarian unless
+// it mentions meat too. Missing meat keywords or allergen numbers prove nothing,
+// so meals without evidence have no diets.
+func mealDiets(meal Meal) []Diet {
+	texts := []string{meal.Name}
+
+	for _, dish := range meal.Dishes {
+		texts = append(texts, dish.Name)
+	}
+
+	text := strings.Join(texts, "\n")
+
+	vegan := hasKeyword(text, string(DietVegan))
+
+	vegetarian := vegan || hasKeyword(text, string(DietVegetarian)) ||
+		hasKeyword(text, keywordsMeatless) && !hasKeyword(text, keywordsMeat)
+
+	glutenFree := hasKeyword(text, string(DietGlutenFree))
+
+	var diets []Diet
+
+	if vegetarian {
+		diets = append(diets, DietVegetarian)
+	}
+
+	if vegan {
+		diets = append(diets, DietVegan)
+	}
+
+	if glutenFree {
+		diets = append(diets, DietGlutenFree)
+	}
+
+	return diets
+}
@@ -0,0 +1,73 @@
+package restaurants
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestClassifyMeals(t *testing.T) {
+	meals := []Meal{
+		{Name: "Menu 1", Dishes: newDishes([]string{"Polievka: Paradajková s ryžou", "Bravčový perkelt, halušky"})},
+		{Name: "Polievka dňa", Dishes: newDishes([]string{"Cesnaková s krutónmi 1,7"})},
+		{Name: "Menu 2", Dishes: newDishes([]string{"Zeleninová krémová 0,33l 9"})},
+		{Name: "Menu 3", Dishes: newDishes([]string{"Caesar šalát s kuracím mäsom"})},
+		{Name: "Menu 4", Dishes: newDishes([]string{"Dezert dňa"})},
+		{Name: "Menu 5", Dishes: newDishes([]string{"Tofu so zeleninou (vegán)"})},
+		{Name: "Menu 6", Dishes: newDishes([]string{"Vyprážaný syr 1,3,7", "hranolky"})},
+		{Name: "Menu 7", Dishes: newDishes([]string{"Kuracie prsia 7", "ryža"})},
+		{Name: "Soups", Dishes: newDishes([]string{"Hovädzí vývar"}), Course: CourseSoup},
+		{Name: "Menu 8", Dishes: newDishes([]string{"Tofu so zeleninou, ryža"})},
+		{Name: "Menu 9", Dishes: newDishes([]string{"Tofu s kuracím mäsom"})},
+		{Name: "Menu 10", Dishes: newDishes([]string{"Vegetariánske rizoto 7"})},
+		{Name: "Menu 11", Dishes: newDishes([]string{"Bezlepkové palacinky s džemom 3,7"})},
+		{Name: "Menu 12", Dishes: newDishes([]string{"Kurací rezeň, krémový špenát, zemiaky"})},
+		{Name: "Menu 13", Dishes: newDishes([]string{"Bravčové na krémovej omáčke s knedľou"})},
+		{Name: "Menu 14", Dishes: newDishes([]string{"Kuracie prsia s ryžou a šalátom"})},
+		{Name: "Menu 15", Dishes: newDishes([]string{"Hovädzí vývar s mäsom a rezancami"})},
+	}
+
+	classifyMeals(meals)
+
+	expected := []struct {
+		course Course
+		diets  []Diet
+	}{
+		{CourseMain, nil},
+		// Soups and desserts without a label may be cooked with meat or gelatine,
+		// and declared allergens don't prove that there is no gluten.
+		{CourseSoup, nil},
+		{CourseSoup, nil},
+		{CourseSalad, nil},
+		{CourseDessert, nil},
+		{CourseMain, []Diet{DietVegetarian, DietVegan}},
+		{CourseMain, nil},
+		{CourseMain, nil},
+		{CourseSoup, nil},
+		{CourseMain, []Diet{DietVegetarian}},
+		{CourseMain, nil},
+		{CourseMain, []Diet{DietVegetarian}},
+		{CourseDessert, []Diet{DietGlutenFree}},
+		// Sides after a comma or a conjunction don't decide the course.
+		{CourseMain, nil},
+		{CourseMain, nil},
+		{CourseMain, nil},
+		{CourseSoup, nil},
+	}
+
+	for index, meal := range meals {
+		if meal.Course != expected[index].course || !slices.Equal(meal.Diets, expected[index].diets) {
+			t.Errorf("meal \"%s\" classified as %s %v, want %s %v", meal.Dishes[0].Name,
+				meal.Course, meal.Diets, expected[index].course, expected[index].diets)
+		}
+	}
+}
+
+func TestParseCourse(t *testing.T) {
+	if course, err := parseCourse("soup"); err != nil || course != CourseSoup {
+		t.Errorf("unexpected result %s, %v", course, err)
+	}
+
+	if _, err := parseCourse("starter"); err == nil {
+		t.Error("expected unknown course to fail")
+	}
+}
@@ -0,0 +1,51 @@
+package restaurants
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DateRange is a period the restaurant is closed, e.g. for a vacation. Both dates
+// are included and formatted with DateLayout, so they compare as strings.
+type DateRange struct {
+	From string
+	To   string
+}
+
+// parseClosedDates reads the "closedDates" config, a list of single dates like
+// "2024-12-27" and of ranges like "2024-07-22/2024-08-02".
+func parseClosedDates(values []string) ([]DateRange, error) {
+	ranges := make([]DateRange, 0, len(values))
+
+	for _, value := range values {
+		from, to, isRange := strings.Cut(value, "/")
+		if !isRange {
+			to = from
+		}
+
+		fromDate, err := time.Parse(DateLayout, strings.TrimSpace(from))
+		if err != nil {
+			return nil, fmt.Errorf("closed date \"%s\" is not in format \"YYYY-MM-DD\"", value)
+		}
+
+		toDate, err := time.Parse(DateLayout, strings.TrimSpace(to))
+		if err != nil {
+			return nil, fmt.Errorf("closed date \"%s\" is not in format \"YYYY-MM-DD\"", value)
+		}
+
+		if toDate.Before(fromDate) {
+			return nil, fmt.Errorf("closed dates \"%s\" end before they start", value)
+		}
+
+		ranges = append(ranges, DateRange{From: fromDate.Format(DateLayout), To: toDate.Format(DateLayout)})
+	}
+
+	return ranges, nil
+}
+
+func (dateRange DateRange) contains(date time.Time) bool {
+	day := date.Format(DateLayout)
+
+	return day >= dateRange.From && day <= dateRange.To
+}
@@ -0,0 +1,170 @@
+package restaurants
+
+import (
+	"context"
+	"menucko/services/clock"
+	"testing"
+	"time"
+)
+
+func TestParseClosedDates(t *testing.T) {
+	ranges, err := parseClosedDates([]string{"2024-12-27", "2024-07-22/2024-08-02"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]bool{
+		"2024-12-27": true,
+		"2024-12-30": false,
+		"2024-07-22": true,
+		"2024-07-30": true,
+		"2024-08-02": true,
+		"2024-08-05": false,
+	}
+
+	for value, want := range tests {
+		date, _ := time.Parse(DateLayout, value)
+
+		closed := false
+		for _, dateRange := range ranges {
+			closed = closed || dateRange.contains(date)
+		}
+
+		if closed != want {
+			t.Errorf("closed on %s = %v, want %v", value, closed, want)
+		}
+	}
+
+	for _, invalid := range []string{"27.12.2024", "2024-08-02/2024-07-22"} {
+		if _, err := parseClosedDates([]string{invalid}); err == nil {
+			t.Errorf("parseClosedDates(\"%s\") didn't fail", invalid)
+		}
+	}
+}
+
+type countingParser struct {
+	calls *int
+}
+
+func (countingParser) ID() string {
+	return "counting"
+}
+
+func (countingParser) Name() string {
+	return "Counting"
+}
+
+func (parser countingParser) Parse(context.Context) (Menu, error) {
+	*parser.calls++
+
+	return Menu{Meals: []Meal{{Name: "Menu 1"}}}, nil
+}
+
+func TestRunnerSkipsClosedDays(t *testing.T) {
+	closedDates, err := parseClosedDates([]string{"2024-04-16"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		date   time.Time
+		calls  int
+		status Status
+	}{
+		{aprilDate(15), 1, StatusOK},
+		{aprilDate(16), 0, StatusClosed},
+		{aprilDate(20), 0, StatusClosed},
+		{time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), 0, StatusClosed},
+	}
+
+	for _, test := range tests {
+		calls := 0
+
+		registry := &Registry{}
+		if err := registry.Register(countingParser{calls: &calls}, 0, closedDates); err != nil {
+			t.Fatal(err)
+		}
+
+		runner := Runner{Registry: registry, Clock: clock.DevClock{Frozen: test.date}}
+
+		menu := runner.Run(context.Background())[0].Menu(test.date)
+
+		if calls != test.calls {
+			t.Errorf("parser was called %d times on %s, want %d", calls, test.date.Format(DateLayout), test.calls)
+		}
+
+		if menu.Status != test.status {
+			t.Errorf("menu on %s has status \"%s\", want \"%s\"", test.date.Format(DateLayout), menu.Status, test.status)
+		}
+	}
+}
+
+type weeklyCountingParser struct {
+	countingParser
+}
+
+func (parser weeklyCountingParser) ParseWeek(context.Context) (WeeklyMenu, error) {
+	*parser.calls++
+
+	week := WeeklyMenu{Days: make(map[string]Menu)}
+
+	for _, date := range WeekDates(time.Date(2024, 4, 29, 0, 0, 0, 0, time.UTC)) {
+		week.Days[date.Format(DateLayout)] = Menu{Status: StatusOK, Meals: []Meal{{Name: "Menu 1"}}}
+	}
+
+	return week, nil
+}
+
+func TestRunnerRunsWeeklyParsersOnHolidays(t *testing.T) {
+	calls := 0
+
+	registry := &Registry{}
+	if err := registry.Register(weeklyCountingParser{countingParser{calls: &calls}}, 0, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	holiday := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	runner := Runner{Registry: registry, Clock: clock.DevClock{Frozen: holiday}}
+
+	week := runner.Run(context.Background())[0]
+
+	if calls != 1 {
+		t.Errorf("weekly parser was called %d times on a holiday, want 1", calls)
+	}
+
+	if menu := week.Menu(holiday); menu.Status != StatusClosed {
+		t.Errorf("menu on the holiday has status \"%s\", want \"%s\"", menu.Status, StatusClosed)
+	}
+
+	if menu := week.Menu(holiday.AddDate(0, 0, 1)); menu.Status != StatusOK || len(menu.Meals) != 1 {
+		t.Errorf("menu after the holiday = %+v, want its meals", menu)
+	}
+}
+
+func TestRunnerSkipsWeeklyParsersClosedAllWeek(t *testing.T) {
+	closedDates, err := parseClosedDates([]string{"2024-04-15/2024-04-22"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	calls := 0
+
+	registry := &Registry{}
+	if err := registry.Register(weeklyCountingParser{countingParser{calls: &calls}}, 0, closedDates); err != nil {
+		t.Fatal(err)
+	}
+
+	runner := Runner{Registry: registry, Clock: clock.DevClock{Frozen: aprilDate(17)}}
+
+	week := runner.Run(context.Background())[0]
+
+	if calls != 0 {
+		t.Errorf("weekly parser was called %d times during a closed week, want 0", calls)
+	}
+
+	for _, date := range pageDates(aprilDate(17)) {
+		if menu := week.Menu(date); menu.Status != StatusClosed {
+			t.Errorf("menu on %s has status \"%s\", want \"%s\"", date.Format(DateLayout), menu.Status, StatusClosed)
+		}
+	}
+}
@@ -2,26 +2,65 @@ package restaurants
 
 import (
 	"bytes"
+	"context"
+	"net/url"
+	"strings"
+	"time"
 
 	"golang.org/x/net/html"
 )
 
+type Parser interface {
+	ID() string
+	Name() string
+	Parse(ctx context.Context) (Menu, error)
+}
+
+type Status string
+
 const (
-	Pizza = iota
-	Lindy
-	Kozel
-	Erika
+	StatusOK           Status = "ok"
+	StatusFailed       Status = "failed"
+	StatusClosed       Status = "closed"
+	StatusStale        Status = "stale"
+	StatusNotPublished Status = "not-published"
 )
 
 type Menu struct {
-	Restaurant int
-	Meals      *[]Meal
+	ID            string
+	Name          string
+	Status        Status
+	ErrorCategory ErrorCategory
+	Error         string
+	SourceURL     string
+	FetchedAt     time.Time
+	ParsedAt      time.Time
+	Meals         []Meal
+	// OCR is set for menus read from an image.
+	OCR *OCRQuality
 }
 
 type Meal struct {
 	Name   string
-	Price  string
-	Dishes []string
+	Price  Price
+	Dishes []Dish
+	// Course comes from the structure of the menu where it has one, otherwise
+	// from keywords in the dish names.
+	Course Course
+	Diets  []Diet `json:",omitempty"`
+	// Confidence is the mean OCR confidence of the lines the meal was read from.
+	Confidence float64 `json:",omitempty"`
+}
+
+// Allergens returns the allergens of all dishes of the meal.
+func (meal Meal) Allergens() []Allergen {
+	var allergens []Allergen
+
+	for _, dish := range meal.Dishes {
+		allergens = append(allergens, dish.Allergens...)
+	}
+
+	return normalizeAllergens(allergens)
 }
 
 func collectHTMLText(node *html.Node, buffer *bytes.Buffer) {
@@ -33,3 +72,17 @@ func collectHTMLText(node *html.Node, buffer *bytes.Buffer) {
 		collectHTMLText(child, buffer)
 	}
 }
+
+func resolveURL(baseURL string, ref string) (string, error) {
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return "", err
+	}
+
+	refURL, err := url.Parse(strings.TrimSpace(ref))
+	if err != nil {
+		return "", err
+	}
+
+	return base.ResolveReference(refURL).String(), nil
+}
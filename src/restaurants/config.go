@@ -0,0 +1,106 @@
+package restaurants
+
+import (
+	"fmt"
+	"menucko/services/clock"
+	"menucko/services/httpclient"
+	"menucko/services/imageocr"
+	"menucko/services/pdftext"
+	"sort"
+	"time"
+
+	"github.com/ericchiang/css"
+)
+
+type Config struct {
+	ID             string            `json:"id"`
+	Name           string            `json:"name"`
+	Type           string            `json:"type"`
+	URL            string            `json:"url"`
+	Order          int               `json:"order"`
+	Timeout        string            `json:"timeout,omitempty"`
+	ClosedKeywords []string          `json:"closedKeywords,omitempty"`
+	ClosedDates    []string          `json:"closedDates,omitempty"`
+	Selectors      map[string]string `json:"selectors,omitempty"`
+	HTML           *HTMLConfig       `json:"html,omitempty"`
+	OCR            *OCRConfig        `json:"ocr,omitempty"`
+}
+
+type Services struct {
+	Clock      clock.Clock
+	HTTPClient httpclient.HTTPClient
+	ImageOcr   imageocr.ImageOcr
+	PDFText    pdftext.PDFText
+}
+
+type Factory func(config Config, services Services) (Parser, error)
+
+var factories = map[string]Factory{
+	HTMLParserType: newHTMLParser,
+	"lindy":        newLindyParser,
+	"erika":        newErikaParser,
+}
+
+func NewRegistry(configs []Config, services Services) (*Registry, error) {
+	sorted := make([]Config, len(configs))
+	copy(sorted, configs)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Order < sorted[j].Order
+	})
+
+	registry := &Registry{}
+
+	for _, config := range sorted {
+		factory, ok := factories[config.Type]
+		if !ok {
+			return nil, fmt.Errorf("restaurant \"%s\" has unknown parser type \"%s\"", config.ID, config.Type)
+		}
+
+		parser, err := factory(config, services)
+		if err != nil {
+			return nil, fmt.Errorf("restaurant \"%s\": %w", config.ID, err)
+		}
+
+		var timeout time.Duration
+
+		if len(config.Timeout) != 0 {
+			if timeout, err = time.ParseDuration(config.Timeout); err != nil {
+				return nil, fmt.Errorf("restaurant \"%s\" has invalid timeout: %w", config.ID, err)
+			}
+		}
+
+		closedDates, err := parseClosedDates(config.ClosedDates)
+		if err != nil {
+			return nil, fmt.Errorf("restaurant \"%s\": %w", config.ID, err)
+		}
+
+		if err := registry.Register(parser, timeout, closedDates); err != nil {
+			return nil, err
+		}
+	}
+
+	return registry, nil
+}
+
+func (config Config) validate(selectorKeys ...string) error {
+	if len(config.ID) == 0 {
+		return fmt.Errorf("restaurant of type \"%s\" has no ID", config.Type)
+	}
+
+	if len(config.URL) == 0 {
+		return fmt.Errorf("restaurant \"%s\" has no URL", config.ID)
+	}
+
+	for _, key := range selectorKeys {
+		if len(config.Selectors[key]) == 0 {
+			return fmt.Errorf("restaurant \"%s\" has no \"%s\" selector", config.ID, key)
+		}
+	}
+
+	return nil
+}
+
+func (config Config) selector(key string) (*css.Selector, error) {
+	return parseSelector(key, config.Selectors[key])
+}
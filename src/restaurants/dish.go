@@ -0,0 +1,124 @@
+package restaurants
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type Unit string
+
+const (
+	UnitGram     Unit = "g"
+	UnitKilogram Unit = "kg"
+	UnitLiter    Unit = "l"
+	UnitPiece    Unit = "ks"
+)
+
+type Dish struct {
+	// Name is the dish without its portion size and allergens.
+	Name string
+	// Quantity and Unit are the portion size, e.g. 0.33 l of soup or 150 g of meat.
+	Quantity  float64    `json:",omitempty"`
+	Unit      Unit       `json:",omitempty"`
+	Allergens []Allergen `json:",omitempty"`
+	// Raw is the text of the dish as found in the menu.
+	Raw string
+}
+
+// Volumes are converted to liters, so that soups can be compared across menus.
+var portionUnits = map[string]struct {
+	unit   Unit
+	factor float64
+}{
+	"g":   {UnitGram, 1},
+	"kg":  {UnitKilogram, 1},
+	"l":   {UnitLiter, 1},
+	"dl":  {UnitLiter, 0.1},
+	"dcl": {UnitLiter, 0.1},
+	"ml":  {UnitLiter, 0.001},
+	"ks":  {UnitPiece, 1},
+}
+
+// Portion sizes are at the start or the end of the dish, or in brackets anywhere,
+// e.g. "0,33l Kurací vývar", "Bravčový rezeň 150 g" or "Rezeň (150g), hranolky".
+var (
+	portionPattern     = `(\d+(?:[.,]\d+)?)\s*(kg|g|dcl|dl|ml|l|ks)\.?`
+	leadingPortionRe   = regexp.MustCompile(`(?i)^` + portionPattern + `(?:\s+|$)`)
+	trailingPortionRe  = regexp.MustCompile(`(?i)(?:^|\s+)` + portionPattern + `$`)
+	bracketedPortionRe = regexp.MustCompile(`(?i)\s*\(` + portionPattern + `\)`)
+	anyPortionRe       = regexp.MustCompile(`(?i)(?:^|[\s(])` + portionPattern + `(?:$|[\s),;])`)
+)
+
+// newDish parses a dish from the text of the menu, every parser should create
+// dishes with it so they are cleaned up the same way.
+func newDish(text string) Dish {
+	raw := strings.TrimSpace(text)
+
+	name, allergens := extractAllergens(raw)
+	name, quantity, unit := extractPortion(name)
+
+	return Dish{
+		Name:      name,
+		Quantity:  quantity,
+		Unit:      unit,
+		Allergens: allergens,
+		Raw:       raw,
+	}
+}
+
+func newDishes(texts []string) []Dish {
+	dishes := make([]Dish, 0, len(texts))
+
+	for _, text := range texts {
+		dishes = append(dishes, newDish(text))
+	}
+
+	return dishes
+}
+
+// extractPortion removes the portion size from the text and returns it, the unit
+// is empty when the text has none. A text with several portions, e.g. "Losos 120 g,
+// zemiaky 200 g", is kept as it is, since none of them is the size of the whole dish.
+func extractPortion(text string) (string, float64, Unit) {
+	if len(anyPortionRe.FindAllStringIndex(text, 2)) > 1 {
+		return text, 0, ""
+	}
+
+	for _, re := range []*regexp.Regexp{leadingPortionRe, trailingPortionRe, bracketedPortionRe} {
+		loc := re.FindStringSubmatchIndex(text)
+		if loc == nil {
+			continue
+		}
+
+		quantity, err := strconv.ParseFloat(strings.ReplaceAll(text[loc[2]:loc[3]], ",", "."), 64)
+		if err != nil {
+			continue
+		}
+
+		portionUnit := portionUnits[strings.ToLower(text[loc[4]:loc[5]])]
+
+		name := strings.TrimSpace(text[:loc[0]] + " " + text[loc[1]:])
+		name = strings.TrimSpace(duplicateWhitespaceRe.ReplaceAllString(name, " "))
+		name = strings.ReplaceAll(name, " ,", ",")
+
+		// Rounding hides the float error of the conversion, e.g. 3 dl to 0.3 l.
+		quantity = math.Round(quantity*portionUnit.factor*1000) / 1000
+
+		return name, quantity, portionUnit.unit
+	}
+
+	return text, 0, ""
+}
+
+// Portion formats the portion size the way Slovak menus do, e.g. "0,33 l".
+func (dish Dish) Portion() string {
+	if len(dish.Unit) == 0 {
+		return ""
+	}
+
+	quantity := strconv.FormatFloat(dish.Quantity, 'f', -1, 64)
+
+	return strings.ReplaceAll(quantity, ".", ",") + " " + string(dish.Unit)
+}
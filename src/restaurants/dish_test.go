@@ -0,0 +1,46 @@
+package restaurants
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestNewDish(t *testing.T) {
+	tests := []struct {
+		text string
+		want Dish
+	}{
+		{"0,33l Kurací vývar", Dish{Name: "Kurací vývar", Quantity: 0.33, Unit: UnitLiter}},
+		{"150g Bravčový rezeň", Dish{Name: "Bravčový rezeň", Quantity: 150, Unit: UnitGram}},
+		{"Hovädzí vývar s rezancami 0,33l", Dish{Name: "Hovädzí vývar s rezancami", Quantity: 0.33, Unit: UnitLiter}},
+		{"Polievka 3 dl 1,9", Dish{Name: "Polievka", Quantity: 0.3, Unit: UnitLiter, Allergens: []Allergen{1, 9}}},
+		{"Rezeň (150g), hranolky 1,3,7", Dish{Name: "Rezeň, hranolky", Quantity: 150, Unit: UnitGram, Allergens: []Allergen{1, 3, 7}}},
+		{"Palacinky 2 ks", Dish{Name: "Palacinky", Quantity: 2, Unit: UnitPiece}},
+		{"Rebierka 0.5kg", Dish{Name: "Rebierka", Quantity: 0.5, Unit: UnitKilogram}},
+		{"Pizza Margherita 32cm", Dish{Name: "Pizza Margherita 32cm"}},
+		{"Gulášová polievka", Dish{Name: "Gulášová polievka"}},
+		{"Losos 120 g, zemiaky 200 g", Dish{Name: "Losos 120 g, zemiaky 200 g"}},
+		{"Rezeň (150g), hranolky (200g)", Dish{Name: "Rezeň (150g), hranolky (200g)"}},
+	}
+
+	for _, test := range tests {
+		dish := newDish(test.text)
+
+		test.want.Raw = test.text
+
+		if dish.Name != test.want.Name || dish.Quantity != test.want.Quantity || dish.Unit != test.want.Unit ||
+			dish.Raw != test.want.Raw || !slices.Equal(dish.Allergens, test.want.Allergens) {
+			t.Errorf("newDish(\"%s\") = %+v, want %+v", test.text, dish, test.want)
+		}
+	}
+}
+
+func TestDishPortion(t *testing.T) {
+	if portion := newDish("0,33l Kurací vývar").Portion(); portion != "0,33 l" {
+		t.Errorf("unexpected portion \"%s\"", portion)
+	}
+
+	if portion := newDish("Kurací vývar").Portion(); portion != "" {
+		t.Errorf("unexpected portion \"%s\"", portion)
+	}
+}
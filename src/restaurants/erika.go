@@ -1,75 +1,83 @@
 package restaurants
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log"
+	"menucko/services/clock"
 	"menucko/services/httpclient"
-	"os"
-	"os/exec"
+	"menucko/services/pdftext"
 	"regexp"
-	"runtime/debug"
 	"strings"
-	"sync"
+	"time"
 
 	"github.com/ericchiang/css"
 	"golang.org/x/net/html"
 )
 
 const erikaLogPrefix = "[Erika]"
-const erikaURL = "https://www.bowlingerika.sk/"
-const erikaPDF = "erika.pdf"
-const erikaTXT = "erika.txt"
 
 type ErikaParser struct {
+	config     Config
+	clock      clock.Clock
 	httpClient httpclient.HTTPClient
+	pdfText    pdftext.PDFText
+
+	menuPdfLinkSelector *css.Selector
 }
 
-func ParseErika(menuChan chan Menu, waitGroup *sync.WaitGroup, httpClient httpclient.HTTPClient) {
-	parser := ErikaParser{
-		httpClient: httpClient,
+func newErikaParser(config Config, services Services) (Parser, error) {
+	if err := config.validate("menuPdfLink"); err != nil {
+		return nil, err
 	}
 
-	defer func() {
-		if rec := recover(); rec == nil {
-			return
-		}
-
-		parser.log("Recovered from panic:\n%s", debug.Stack())
+	menuPdfLinkSelector, err := config.selector("menuPdfLink")
+	if err != nil {
+		return nil, err
+	}
 
-		menuChan <- Menu{
-			Restaurant: Erika,
-			Meals:      nil,
-		}
-	}()
+	return ErikaParser{
+		config:              config,
+		clock:               services.Clock,
+		httpClient:          services.HTTPClient,
+		pdfText:             services.PDFText,
+		menuPdfLinkSelector: menuPdfLinkSelector,
+	}, nil
+}
 
-	defer waitGroup.Done()
+func (parser ErikaParser) ID() string {
+	return parser.config.ID
+}
 
-	meals, err := parser.parseMenu()
-	if err != nil {
-		parser.log("Err: %v", err)
+func (parser ErikaParser) Name() string {
+	return parser.config.Name
+}
 
-		menuChan <- Menu{
-			Restaurant: Erika,
-			Meals:      nil,
-		}
-		return
+func (parser ErikaParser) Parse(ctx context.Context) (Menu, error) {
+	menu := Menu{
+		SourceURL: parser.config.URL,
 	}
 
-	menuChan <- Menu{
-		Restaurant: Erika,
-		Meals:      meals,
-	}
+	meals, err := parser.parseMenu(ctx, &menu)
+
+	classifyMeals(meals)
+
+	menu.Meals = meals
+
+	return menu, err
 }
 
-func (parser ErikaParser) parseMenu() (*[]Meal, error) {
-	parser.log("Downloading HTML from URL \"%s\"", erikaURL)
+func (parser ErikaParser) parseMenu(ctx context.Context, menu *Menu) ([]Meal, error) {
+	parser.log("Downloading HTML from URL \"%s\"", parser.config.URL)
 
-	htmlContent, err := parser.httpClient.DownloadHTML(erikaURL)
+	htmlContent, err := parser.httpClient.DownloadHTML(ctx, parser.config.URL)
 	if err != nil {
 		return nil, err
 	}
 
+	menu.FetchedAt = time.Now()
+
 	parser.log("Parsing HTML from a string with length %d", len(htmlContent))
 
 	rootNode, err := html.Parse(strings.NewReader(htmlContent))
@@ -77,11 +85,9 @@ func (parser ErikaParser) parseMenu() (*[]Meal, error) {
 		return nil, err
 	}
 
-	menuPdfLinkSelector := css.MustParse("#denne-menu .elementor-button-link")
-
 	parser.log("Selecting daily menu PDF anchor element with CSS selector")
 
-	menuPdfLinkEls := menuPdfLinkSelector.Select(rootNode)
+	menuPdfLinkEls := parser.menuPdfLinkSelector.Select(rootNode)
 	if len(menuPdfLinkEls) == 0 {
 		return nil, errors.New("daily menu PDF anchor selector didn't match any element")
 	}
@@ -99,57 +105,66 @@ func (parser ErikaParser) parseMenu() (*[]Meal, error) {
 		return nil, errors.New("\"a\" daily menu element has no \"href\" attribute")
 	}
 
-	parser.log("Downloading PDF from URL \"%s\"", aHref)
-
-	pdfContent, err := parser.httpClient.DownloadHTML(aHref)
+	pdfURL, err := resolveURL(parser.config.URL, aHref)
 	if err != nil {
 		return nil, err
 	}
 
-	defer parser.cleanUpFiles()
+	parser.log("Downloading PDF from URL \"%s\"", pdfURL)
 
-	parser.log("Saving PDF for parsing")
-
-	err = parser.savePDF(&pdfContent)
+	pdfRes, err := parser.httpClient.Download(ctx, pdfURL)
 	if err != nil {
 		return nil, err
 	}
 
-	parser.log("Parsing PDF content")
+	parser.log("Extracting text runs from PDF with length %d", len(pdfRes.Body))
 
-	menuText, err := parser.parsePDF()
+	runs, err := parser.pdfText.ExtractRuns(ctx, pdfRes.Body)
 	if err != nil {
 		return nil, err
 	}
 
-	lines := strings.Split(menuText, "\n")
+	rows := pdftext.Rows(runs)
+
+	rowTexts := make([]string, len(rows))
+	for index, row := range rows {
+		rowTexts[index] = row.Text()
+	}
+
+	menuText := strings.Join(rowTexts, "\n")
+
+	if err = checkClosed(parser.config, menuText); err != nil {
+		return nil, err
+	}
+
+	if err = checkStale(menuText, clock.Today(parser.clock)); err != nil {
+		return nil, err
+	}
 
-	parser.log("Parsing %d lines into individual meals", len(lines))
+	parser.log("Parsing %d rows into individual meals", len(rows))
 
 	meals := make([]Meal, 0)
 	var currMeal *Meal
 	var mealLength int
 
-	for _, line := range lines {
-		cleanedLine := strings.TrimSpace(line)
+	for index, row := range rows {
+		text, price := parser.splitRow(row)
 
-		if len(cleanedLine) == 0 {
+		if len(text) == 0 && price.IsZero() {
 			continue
 		}
 
-		if currMeal == nil {
-			name, dish := parser.parseFirstLine(cleanedLine)
-
-			if len(name) == 0 {
-				continue
-			}
+		if currMeal != nil && index > 0 && parser.isSeparated(rows[index-1], row) {
+			currMeal = nil
+		}
 
+		if name, dish := parser.parseFirstLine(text); len(name) != 0 {
 			parser.log("Found first line for menu \"%s\"", name)
 
 			meals = append(meals, Meal{
 				Name:   name,
-				Price:  "",
-				Dishes: []string{dish},
+				Price:  price,
+				Dishes: []Dish{newDish(dish)},
 			})
 
 			currMeal = &meals[len(meals)-1]
@@ -159,9 +174,16 @@ func (parser ErikaParser) parseMenu() (*[]Meal, error) {
 			continue
 		}
 
-		price := parser.parsePrice(cleanedLine)
-		if len(price) != 0 {
-			currMeal.Price = price
+		if currMeal == nil {
+			continue
+		}
+
+		// A row with only a price closes the meal, which is how single column
+		// menus are laid out. Table layouts put the price next to the first dish.
+		if len(text) == 0 {
+			if currMeal.Price.IsZero() {
+				currMeal.Price = price
+			}
 
 			currMeal = nil
 
@@ -172,58 +194,16 @@ func (parser ErikaParser) parseMenu() (*[]Meal, error) {
 			break
 		}
 
-		currMeal.Dishes = append(currMeal.Dishes, cleanedLine)
-
-		mealLength++
-
-	}
-
-	return &meals, nil
-}
-
-func (parser ErikaParser) savePDF(content *string) error {
-	file, err := os.Create(erikaPDF)
-	if err != nil {
-		return err
-	}
+		currMeal.Dishes = append(currMeal.Dishes, newDish(text))
 
-	defer func() {
-		if err := file.Close(); err != nil {
-			panic(err)
+		if currMeal.Price.IsZero() {
+			currMeal.Price = price
 		}
-	}()
-
-	_, err = file.WriteString(*content)
-
-	return err
-}
-
-func (parser ErikaParser) parsePDF() (string, error) {
-	cmd := exec.Command("pdftotext", erikaPDF, erikaTXT)
 
-	_, err := cmd.Output()
-	if err != nil {
-		return "", err
-	}
-
-	fileContent, err := os.ReadFile(erikaTXT)
-	if err != nil {
-		return "", err
-	}
-
-	return string(fileContent), nil
-}
-
-func (parser ErikaParser) cleanUpFiles() {
-	parser.log("Cleaning up files")
-
-	if _, err := os.Stat(erikaPDF); err == nil {
-		_ = os.Remove(erikaPDF)
+		mealLength++
 	}
 
-	if _, err := os.Stat(erikaTXT); err == nil {
-		_ = os.Remove(erikaTXT)
-	}
+	return meals, nil
 }
 
 func (parser ErikaParser) parseFirstLine(line string) (string, string) {
@@ -240,15 +220,44 @@ func (parser ErikaParser) parseFirstLine(line string) (string, string) {
 	return name, dish
 }
 
-func (parser ErikaParser) parsePrice(line string) string {
-	if !strings.Contains(line, "€") {
-		return ""
+// splitRow separates the price cell from the rest of the row, the price is
+// paired with the dish text by being on the same row.
+func (parser ErikaParser) splitRow(row pdftext.Row) (string, Price) {
+	var texts []string
+	var price Price
+
+	for _, cell := range row.Cells {
+		if cellPrice := parser.parsePrice(cell.Text); !cellPrice.IsZero() {
+			price = cellPrice
+			continue
+		}
+
+		texts = append(texts, cell.Text)
+	}
+
+	return strings.TrimSpace(strings.Join(texts, " ")), price
+}
+
+// isSeparated reports whether there is a visible gap between the rows or the
+// row starts a new column or page, either of which ends the current meal.
+func (parser ErikaParser) isSeparated(previous, row pdftext.Row) bool {
+	if previous.Page != row.Page {
+		return true
 	}
 
-	price := strings.ReplaceAll(line, ".", ",")
+	distance := previous.Y - row.Y
 
-	return price
+	return distance < 0 || distance > 1.8*row.FontSize
+}
+
+// parsePrice treats cells with the euro sign as prices, the price is kept as raw
+// text when the cell has more than the amount.
+func (parser ErikaParser) parsePrice(line string) Price {
+	if !strings.Contains(line, "€") {
+		return Price{}
+	}
 
+	return ParsePrice(line)
 }
 
 func (parser ErikaParser) log(format string, v ...any) {
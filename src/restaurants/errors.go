@@ -0,0 +1,110 @@
+package restaurants
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"menucko/services/httpclient"
+	"net"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrClosed = errors.New("restaurant is closed today")
+var ErrNotPublished = errors.New("menu for today is not published yet")
+var ErrStale = errors.New("published menu is not for today")
+
+type ErrorCategory string
+
+const (
+	ErrorCategoryNone    ErrorCategory = ""
+	ErrorCategoryNetwork ErrorCategory = "network"
+	ErrorCategoryHTTP    ErrorCategory = "http"
+	ErrorCategoryTimeout ErrorCategory = "timeout"
+	ErrorCategoryParse   ErrorCategory = "parse"
+	ErrorCategoryPanic   ErrorCategory = "panic"
+)
+
+func statusFromError(err error) (Status, ErrorCategory) {
+	switch {
+	case err == nil:
+		return StatusOK, ErrorCategoryNone
+	case errors.Is(err, ErrClosed):
+		return StatusClosed, ErrorCategoryNone
+	case errors.Is(err, ErrNotPublished):
+		return StatusNotPublished, ErrorCategoryNone
+	case errors.Is(err, ErrStale):
+		return StatusStale, ErrorCategoryNone
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled):
+		return StatusFailed, ErrorCategoryTimeout
+	}
+
+	var statusErr *httpclient.StatusError
+	if errors.As(err, &statusErr) {
+		return StatusFailed, ErrorCategoryHTTP
+	}
+
+	var urlErr *url.Error
+	var netErr net.Error
+
+	if errors.As(err, &urlErr) || errors.As(err, &netErr) {
+		return StatusFailed, ErrorCategoryNetwork
+	}
+
+	return StatusFailed, ErrorCategoryParse
+}
+
+// checkClosed reports ErrClosed when the menu text contains one of the configured
+// "closedKeywords", e.g. "zatvorené" or "sviatok". Matching is case-insensitive.
+func checkClosed(config Config, text string) error {
+	text = strings.ToLower(text)
+
+	for _, keyword := range config.ClosedKeywords {
+		if strings.Contains(text, strings.ToLower(keyword)) {
+			return ErrClosed
+		}
+	}
+
+	return nil
+}
+
+// Dates on menus look like "16.04.2024" or "16. 4. 2024".
+var menuDateRe = regexp.MustCompile(`\b(\d{1,2})\.\s*(\d{1,2})\.\s*(\d{4})\b`)
+
+// checkStale reports ErrStale when the menu text is dated and today isn't within
+// the dates, e.g. a restaurant which didn't replace yesterday's menu yet. A single
+// date has to be today, several dates are read as the range the menu is valid for.
+// Menus without a date are never stale.
+func checkStale(text string, today time.Time) error {
+	var first, last time.Time
+
+	for _, match := range menuDateRe.FindAllStringSubmatch(text, -1) {
+		day, _ := strconv.Atoi(match[1])
+		month, _ := strconv.Atoi(match[2])
+		year, _ := strconv.Atoi(match[3])
+
+		date := time.Date(year, time.Month(month), day, 0, 0, 0, 0, today.Location())
+
+		// Rejects dates like "31.04.2024", which time.Date normalizes.
+		if date.Day() != day || int(date.Month()) != month {
+			continue
+		}
+
+		if first.IsZero() || date.Before(first) {
+			first = date
+		}
+
+		if last.IsZero() || date.After(last) {
+			last = date
+		}
+	}
+
+	if first.IsZero() || !today.Before(first) && !today.After(last) {
+		return nil
+	}
+
+	return fmt.Errorf("%w, it's dated %s", ErrStale, first.Format(DateLayout))
+}
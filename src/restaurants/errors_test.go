@@ -0,0 +1,29 @@
+package restaurants
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckStale(t *testing.T) {
+	tests := []struct {
+		text  string
+		stale bool
+	}{
+		{"Denné menu 16.04.2024 (utorok)", false},
+		{"Denné menu 16. 4. 2024", false},
+		{"Denné menu 15.04.2024 (pondelok)", true},
+		{"Menu platné 15.04.2024 - 19.04.2024", false},
+		{"Menu platné 8.04.2024 - 12.04.2024", true},
+		{"Denné menu", false},
+		{"Denné menu 31.04.2024", false},
+	}
+
+	for _, test := range tests {
+		err := checkStale(test.text, aprilDate(16))
+
+		if errors.Is(err, ErrStale) != test.stale {
+			t.Errorf("checkStale(\"%s\") = %v, want stale %v", test.text, err, test.stale)
+		}
+	}
+}
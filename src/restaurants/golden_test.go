@@ -0,0 +1,213 @@
+package restaurants
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"menucko/services/clock"
+	"menucko/services/httpclient"
+	"menucko/services/imageocr"
+	"menucko/services/pdftext"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// The golden tests run every restaurant from the config file against fixtures in
+// "testdata/<restaurant ID>/<YYYY-MM-DD>/". A fixture directory is recorded with
+// MENUCKO_HTTP_RECORD_DIR, see "testdata/README.md" for the entries still written
+// by hand, and contains:
+//   - "index.json" and the recorded bodies, served by httpclient.ReplayHTTPClient
+//   - "ocr.txt" with the text of the menu image, if the restaurant uses OCR
+//   - or "ocr.json" with the recognised words and their bounding boxes instead
+//   - "expected.json" with the expected meals, regenerated with "go test -update"
+//   - "expected-week.json" with the meals of every day, for restaurants publishing
+//     the whole week
+var update = flag.Bool("update", false, "regenerate the golden files")
+
+const configPath = "../../config/menucko.json"
+const goldenFile = "expected.json"
+const weekGoldenFile = "expected-week.json"
+const ocrFile = "ocr.txt"
+const ocrWordsFile = "ocr.json"
+
+func TestGolden(t *testing.T) {
+	for _, config := range loadRestaurantConfigs(t) {
+		dirs, err := filepath.Glob(filepath.Join("testdata", config.ID, "*"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, dir := range dirs {
+			config, dir := config, dir
+
+			t.Run(config.ID+"/"+filepath.Base(dir), func(t *testing.T) {
+				runGolden(t, config, dir)
+			})
+		}
+	}
+}
+
+func runGolden(t *testing.T, config Config, dir string) {
+	date, err := time.Parse("2006-01-02", filepath.Base(dir))
+	if err != nil {
+		t.Fatalf("fixture directory name is not a date: %v", err)
+	}
+
+	httpClient, err := httpclient.NewReplayHTTPClient(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ocrText, err := os.ReadFile(filepath.Join(dir, ocrFile))
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+
+	ocr := imageocr.DevImageOcr{ImgText: string(ocrText)}
+
+	ocrWords, err := os.ReadFile(filepath.Join(dir, ocrWordsFile))
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+
+	if len(ocrWords) > 0 {
+		var words []imageocr.Word
+		if err = json.Unmarshal(ocrWords, &words); err != nil {
+			t.Fatal(err)
+		}
+
+		ocr.Lines = imageocr.ReadingOrder(words)
+	}
+
+	services := Services{
+		Clock:      clock.DevClock{Frozen: date},
+		HTTPClient: httpClient,
+		ImageOcr:   ocr,
+		PDFText:    pdftext.ProdPDFText{},
+	}
+
+	parser, err := factories[config.Type](config, services)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	menu, err := parser.Parse(context.Background())
+	if err != nil {
+		t.Fatalf("parsing failed: %v", err)
+	}
+
+	compareGolden(t, filepath.Join(dir, goldenFile), menu.Meals)
+
+	weeklyParser, ok := parser.(WeeklyParser)
+	if !ok {
+		return
+	}
+
+	week, err := weeklyParser.ParseWeek(context.Background())
+	if err != nil {
+		t.Fatalf("parsing the week failed: %v", err)
+	}
+
+	type goldenDay struct {
+		Status Status
+		Meals  []Meal
+	}
+
+	days := make(map[string]goldenDay)
+
+	for date, dayMenu := range week.Days {
+		days[date] = goldenDay{Status: dayMenu.Status, Meals: dayMenu.Meals}
+	}
+
+	compareGolden(t, filepath.Join(dir, weekGoldenFile), days)
+}
+
+func compareGolden(t *testing.T, goldenPath string, value any) {
+	t.Helper()
+
+	actual, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actual = append(actual, '\n')
+
+	if *update {
+		if err := os.WriteFile(goldenPath, actual, 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		return
+	}
+
+	expected, err := os.ReadFile(goldenPath)
+	if err != nil {
+		t.Fatalf("golden file is missing, run the tests with -update: %v", err)
+	}
+
+	if string(expected) != string(actual) {
+		t.Errorf("result doesn't match \"%s\" (- expected, + actual):\n%s", goldenPath, diffLines(string(expected), string(actual)))
+	}
+}
+
+func loadRestaurantConfigs(t *testing.T) []Config {
+	content, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var config struct {
+		Restaurants []Config `json:"restaurants"`
+	}
+
+	if err = json.Unmarshal(content, &config); err != nil {
+		t.Fatal(err)
+	}
+
+	return config.Restaurants
+}
+
+// diffLines returns a line diff based on the longest common subsequence.
+func diffLines(expected string, actual string) string {
+	a := strings.Split(expected, "\n")
+	b := strings.Split(actual, "\n")
+
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	diff := &strings.Builder{}
+
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			fmt.Fprintf(diff, "  %s\n", a[i])
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			fmt.Fprintf(diff, "- %s\n", a[i])
+			i++
+		default:
+			fmt.Fprintf(diff, "+ %s\n", b[j])
+			j++
+		}
+	}
+
+	return diff.String()
+}
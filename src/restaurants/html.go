@@ -0,0 +1,448 @@
+package restaurants
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"menucko/services/clock"
+	"menucko/services/httpclient"
+	"strings"
+	"time"
+
+	"github.com/ericchiang/css"
+	"golang.org/x/net/html"
+)
+
+const HTMLParserType = "html"
+
+const (
+	// Day containers are matched by their position, the selector may contain a "%d"
+	// placeholder for the 1-based weekday (e.g. ":nth-of-type(%d)").
+	DayMatchWeekdayIndex = "weekday-index"
+	// Day containers are matched by the text of their heading starting with the day name.
+	DayMatchDayName = "day-name"
+)
+
+type HTMLConfig struct {
+	Day    HTMLDayConfig     `json:"day"`
+	Groups []HTMLGroupConfig `json:"groups"`
+}
+
+type HTMLDayConfig struct {
+	Selector string `json:"selector"`
+	Match    string `json:"match"`
+	Heading  string `json:"heading"`
+}
+
+type HTMLGroupConfig struct {
+	Meals    string `json:"meals"`
+	Name     string `json:"name"`
+	Price    string `json:"price"`
+	Dishes   string `json:"dishes"`
+	Merge    bool   `json:"merge"`
+	Required bool   `json:"required"`
+	// Course is set on all meals of the group, e.g. "soup" for a list of soups.
+	Course string `json:"course,omitempty"`
+}
+
+type HTMLParser struct {
+	config     Config
+	clock      clock.Clock
+	httpClient httpclient.HTTPClient
+
+	daySelector     *css.Selector
+	headingSelector *css.Selector
+	groups          []htmlGroup
+}
+
+type htmlGroup struct {
+	config         HTMLGroupConfig
+	course         Course
+	mealsSelector  *css.Selector
+	nameSelector   *css.Selector
+	priceSelector  *css.Selector
+	dishesSelector *css.Selector
+}
+
+func newHTMLParser(config Config, services Services) (Parser, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+
+	if config.HTML == nil {
+		return nil, errors.New("HTML parser has no \"html\" config")
+	}
+
+	parser := HTMLParser{
+		config:     config,
+		clock:      services.Clock,
+		httpClient: services.HTTPClient,
+	}
+
+	var err error
+
+	day := config.HTML.Day
+
+	switch day.Match {
+	case DayMatchWeekdayIndex:
+		if !strings.Contains(day.Selector, "%d") {
+			if parser.daySelector, err = parseSelector("day.selector", day.Selector); err != nil {
+				return nil, err
+			}
+		}
+
+	case DayMatchDayName:
+		if parser.daySelector, err = parseSelector("day.selector", day.Selector); err != nil {
+			return nil, err
+		}
+
+		if parser.headingSelector, err = parseSelector("day.heading", day.Heading); err != nil {
+			return nil, err
+		}
+
+	default:
+		return nil, fmt.Errorf("day match \"%s\" is unknown", day.Match)
+	}
+
+	if len(config.HTML.Groups) == 0 {
+		return nil, errors.New("HTML parser has no meal groups")
+	}
+
+	for index, groupConfig := range config.HTML.Groups {
+		group, err := newHTMLGroup(index, groupConfig)
+		if err != nil {
+			return nil, err
+		}
+
+		parser.groups = append(parser.groups, group)
+	}
+
+	return parser, nil
+}
+
+func newHTMLGroup(index int, config HTMLGroupConfig) (htmlGroup, error) {
+	group := htmlGroup{config: config}
+
+	var err error
+
+	if group.course, err = parseCourse(config.Course); err != nil {
+		return group, fmt.Errorf("group %d: %w", index, err)
+	}
+
+	if group.mealsSelector, err = parseSelector(fmt.Sprintf("groups[%d].meals", index), config.Meals); err != nil {
+		return group, err
+	}
+
+	if group.nameSelector, err = parseSelector(fmt.Sprintf("groups[%d].name", index), config.Name); err != nil {
+		return group, err
+	}
+
+	if group.dishesSelector, err = parseSelector(fmt.Sprintf("groups[%d].dishes", index), config.Dishes); err != nil {
+		return group, err
+	}
+
+	if len(config.Price) == 0 {
+		if !config.Merge {
+			return group, fmt.Errorf("selector \"groups[%d].price\" is required unless the group is merged", index)
+		}
+
+		return group, nil
+	}
+
+	if group.priceSelector, err = parseSelector(fmt.Sprintf("groups[%d].price", index), config.Price); err != nil {
+		return group, err
+	}
+
+	return group, nil
+}
+
+func (parser HTMLParser) ID() string {
+	return parser.config.ID
+}
+
+func (parser HTMLParser) Name() string {
+	return parser.config.Name
+}
+
+func (parser HTMLParser) Parse(ctx context.Context) (Menu, error) {
+	menu := Menu{
+		SourceURL: parser.config.URL,
+	}
+
+	meals, err := parser.parseMenu(ctx, &menu)
+
+	classifyMeals(meals)
+
+	menu.Meals = meals
+
+	return menu, err
+}
+
+// ParseWeek reads the menus of all working days the page has, days without a menu
+// element are left out. When the page is closed, e.g. with a vacation notice outside
+// the day elements, the days without a menu element are closed instead, like in Parse.
+func (parser HTMLParser) ParseWeek(ctx context.Context) (WeeklyMenu, error) {
+	week := WeeklyMenu{
+		SourceURL: parser.config.URL,
+		Days:      make(map[string]Menu),
+	}
+
+	rootNode, err := parser.downloadPage(ctx)
+	if err != nil {
+		return week, err
+	}
+
+	week.FetchedAt = time.Now()
+
+	closedErr := checkClosed(parser.config, nodeText(rootNode))
+
+	for _, date := range WeekDates(clock.Today(parser.clock)) {
+		if !clock.IsWorkday(date) {
+			continue
+		}
+
+		var meals []Meal
+
+		menuEl, err := parser.findDailyMenuEl(rootNode, date)
+
+		switch {
+		case err != nil && closedErr != nil:
+			err = closedErr
+		case err != nil:
+			parser.log("Skipping %s, Err: %v", date.Format(DateLayout), err)
+			continue
+		default:
+			meals, err = parser.parseDay(menuEl)
+		}
+
+		menu := dayMenu(meals, err)
+		menu.SourceURL = week.SourceURL
+		menu.FetchedAt = week.FetchedAt
+
+		week.Days[date.Format(DateLayout)] = menu
+	}
+
+	return week, nil
+}
+
+func (parser HTMLParser) parseMenu(ctx context.Context, menu *Menu) ([]Meal, error) {
+	rootNode, err := parser.downloadPage(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	menu.FetchedAt = time.Now()
+
+	menuEl, err := parser.findDailyMenuEl(rootNode, clock.Today(parser.clock))
+	if err != nil {
+		if closedErr := checkClosed(parser.config, nodeText(rootNode)); closedErr != nil {
+			return nil, closedErr
+		}
+
+		return nil, err
+	}
+
+	return parser.parseDay(menuEl)
+}
+
+func (parser HTMLParser) downloadPage(ctx context.Context) (*html.Node, error) {
+	parser.log("Downloading HTML from URL \"%s\"", parser.config.URL)
+
+	htmlContent, err := parser.httpClient.DownloadHTML(ctx, parser.config.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	parser.log("Parsing HTML from a string with length %d", len(htmlContent))
+
+	return html.Parse(strings.NewReader(htmlContent))
+}
+
+func (parser HTMLParser) parseDay(menuEl *html.Node) ([]Meal, error) {
+	if err := checkClosed(parser.config, nodeText(menuEl)); err != nil {
+		return nil, err
+	}
+
+	meals := make([]Meal, 0)
+
+	for groupIndex, group := range parser.groups {
+		mealEls := group.mealsSelector.Select(menuEl)
+		if len(mealEls) == 0 {
+			if group.config.Required {
+				return nil, fmt.Errorf("CSS selector \"%s\" for meals didn't match any element", group.config.Meals)
+			}
+
+			continue
+		}
+
+		parser.log("Parsing %d meal elements of group %d", len(mealEls), groupIndex)
+
+		if group.config.Merge {
+			meal := parser.parseMergedMeal(group, mealEls)
+
+			if len(meal.Dishes) > 0 {
+				meals = append(meals, meal)
+			}
+
+			continue
+		}
+
+		for index, mealEl := range mealEls {
+			name := selectText(group.nameSelector, mealEl)
+			price := parser.parsePrice(group, mealEl)
+			dishes := selectTexts(group.dishesSelector, mealEl)
+
+			if len(name) == 0 || price.IsZero() || len(dishes) == 0 {
+				parser.log("Meal with index %d has no name, price or dishes", index)
+				continue
+			}
+
+			parser.log("Parsed meal with name \"%s\"", name)
+
+			meals = append(meals, Meal{
+				Name:   name,
+				Price:  price,
+				Dishes: newDishes(dishes),
+				Course: group.course,
+			})
+		}
+	}
+
+	return meals, nil
+}
+
+func (parser HTMLParser) findDailyMenuEl(rootNode *html.Node, date time.Time) (*html.Node, error) {
+	day := parser.config.HTML.Day
+
+	if day.Match == DayMatchWeekdayIndex {
+		return parser.findDailyMenuElByIndex(rootNode, clock.Weekday(date))
+	}
+
+	parser.log("Selecting daily menu elements")
+
+	menuEls := parser.daySelector.Select(rootNode)
+	if len(menuEls) == 0 {
+		return nil, fmt.Errorf("daily menu CSS selector \"%s\" didn't match any element", day.Selector)
+	}
+
+	dayName := strings.ToLower(clock.DayName(date))
+
+	parser.log("Looking for the daily menu element for day \"%s\"", dayName)
+
+	for _, menuEl := range menuEls {
+		heading := strings.ToLower(selectText(parser.headingSelector, menuEl))
+
+		if strings.HasPrefix(heading, dayName) {
+			return menuEl, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no daily menu element has a heading starting with \"%s\": %w", dayName, ErrNotPublished)
+}
+
+func (parser HTMLParser) findDailyMenuElByIndex(rootNode *html.Node, weekday int) (*html.Node, error) {
+	day := parser.config.HTML.Day
+
+	if parser.daySelector != nil {
+		parser.log("Selecting daily menu element with index %d", weekday)
+
+		menuEls := parser.daySelector.Select(rootNode)
+		if len(menuEls) <= weekday {
+			return nil, fmt.Errorf("daily menu CSS selector \"%s\" matched only %d elements", day.Selector, len(menuEls))
+		}
+
+		return menuEls[weekday], nil
+	}
+
+	menuSelectorStr := fmt.Sprintf(day.Selector, weekday+1)
+
+	menuSelector, err := css.Parse(menuSelectorStr)
+	if err != nil {
+		return nil, err
+	}
+
+	parser.log("Selecting daily menu element with CSS selector \"%s\"", menuSelectorStr)
+
+	menuEls := menuSelector.Select(rootNode)
+	if len(menuEls) == 0 {
+		return nil, fmt.Errorf("daily menu CSS selector \"%s\" didn't match any element", menuSelectorStr)
+	}
+
+	return menuEls[0], nil
+}
+
+func (parser HTMLParser) parseMergedMeal(group htmlGroup, mealEls []*html.Node) Meal {
+	meal := Meal{
+		Name:   selectText(group.nameSelector, mealEls[0]),
+		Price:  Price{},
+		Dishes: []Dish{},
+		Course: group.course,
+	}
+
+	if group.priceSelector != nil {
+		meal.Price = parser.parsePrice(group, mealEls[0])
+	}
+
+	for _, mealEl := range mealEls {
+		meal.Dishes = append(meal.Dishes, newDishes(selectTexts(group.dishesSelector, mealEl))...)
+	}
+
+	return meal
+}
+
+func (parser HTMLParser) parsePrice(group htmlGroup, mealEl *html.Node) Price {
+	return ParsePrice(selectText(group.priceSelector, mealEl))
+}
+
+func (parser HTMLParser) log(format string, v ...any) {
+	message := "[" + parser.config.Name + "] " + fmt.Sprintf(format, v...)
+
+	log.Println(message)
+}
+
+func parseSelector(key string, value string) (*css.Selector, error) {
+	if len(value) == 0 {
+		return nil, fmt.Errorf("selector \"%s\" is empty", key)
+	}
+
+	selector, err := css.Parse(value)
+	if err != nil {
+		return nil, fmt.Errorf("selector \"%s\" with value \"%s\" is invalid: %w", key, value, err)
+	}
+
+	return selector, nil
+}
+
+func selectText(selector *css.Selector, node *html.Node) string {
+	els := selector.Select(node)
+	if len(els) == 0 {
+		return ""
+	}
+
+	return nodeText(els[0])
+}
+
+func nodeText(node *html.Node) string {
+	buffer := &bytes.Buffer{}
+	collectHTMLText(node, buffer)
+
+	return strings.TrimSpace(buffer.String())
+}
+
+func selectTexts(selector *css.Selector, node *html.Node) []string {
+	var texts []string
+
+	for _, el := range selector.Select(node) {
+		text := nodeText(el)
+
+		if len(text) == 0 {
+			continue
+		}
+
+		texts = append(texts, text)
+	}
+
+	return texts
+}
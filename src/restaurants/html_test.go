@@ -0,0 +1,83 @@
+package restaurants
+
+import (
+	"context"
+	"menucko/services/clock"
+	"menucko/services/httpclient"
+	"testing"
+)
+
+func TestHTMLParserClosedPage(t *testing.T) {
+	config := Config{
+		ID:             "test",
+		Type:           HTMLParserType,
+		URL:            "https://example.com",
+		ClosedKeywords: []string{"dovolenka"},
+		HTML: &HTMLConfig{
+			Day:    HTMLDayConfig{Selector: "#menu .day:nth-of-type(%d)", Match: DayMatchWeekdayIndex},
+			Groups: []HTMLGroupConfig{{Meals: ".meal", Name: ".name", Price: ".price", Dishes: ".dish", Required: true}},
+		},
+	}
+
+	services := Services{
+		Clock:      clock.DevClock{Frozen: aprilDate(16)},
+		HTTPClient: httpclient.DevHTTPClient{HTMLContent: `<p class="notice">Celozávodná dovolenka do 19.4.</p><div id="menu"></div>`},
+	}
+
+	parser, err := newHTMLParser(config, services)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	week, err := parser.(WeeklyParser).ParseWeek(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, date := range WeekDates(aprilDate(16)) {
+		if menu := week.Menu(date); menu.Status != StatusClosed {
+			t.Errorf("menu on %s has status \"%s\", want \"%s\"", date.Format(DateLayout), menu.Status, StatusClosed)
+		}
+	}
+}
+
+// Only the first paragraph of a Kozel meal is its dish, the way the dedicated Kozel
+// parser read it before the generic HTML parser replaced it.
+func TestKozelReadsFirstParagraph(t *testing.T) {
+	var config Config
+
+	for _, restaurant := range loadRestaurantConfigs(t) {
+		if restaurant.ID == "kozel" {
+			config = restaurant
+		}
+	}
+
+	services := Services{
+		Clock: clock.DevClock{Frozen: aprilDate(16)},
+		HTTPClient: httpclient.DevHTTPClient{HTMLContent: `<div class="entry-content"><div class="daily-menu">
+			<h3>Utorok 16.4.</h3>
+			<div class="polievky"><div class="menu-holder"><span>Polievka</span><p>Cesnaková</p><p>Obsahuje lepok</p></div></div>
+			<div class="hlavne"><div class="menu-holder"><span>Menu 1</span><span class="menu-price">6.50</span><p>Kurací rezeň, zemiaky</p><p>Novinka</p></div></div>
+		</div></div>`},
+	}
+
+	parser, err := newHTMLParser(config, services)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	menu, err := parser.Parse(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(menu.Meals) != 2 {
+		t.Fatalf("parsed %d meals, want 2", len(menu.Meals))
+	}
+
+	for _, meal := range menu.Meals {
+		if len(meal.Dishes) != 1 {
+			t.Errorf("meal \"%s\" has dishes %+v, want only the first paragraph", meal.Name, meal.Dishes)
+		}
+	}
+}
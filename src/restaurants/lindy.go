@@ -1,74 +1,90 @@
 package restaurants
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log"
 	"menucko/services/httpclient"
 	"menucko/services/imageocr"
 	"regexp"
-	"runtime/debug"
 	"strings"
-	"sync"
+	"time"
 
 	"github.com/ericchiang/css"
 	"golang.org/x/net/html"
 )
 
 const lindyLogPrefix = "[Lindy]"
-const lindyURL = "http://www.lindyhop.sk/"
 
 type LindyParser struct {
+	config     Config
 	httpClient httpclient.HTTPClient
 	imageOcr   imageocr.ImageOcr
+
+	menuImgSelector *css.Selector
 }
 
-func ParseLindy(menuChan chan Menu, waitGroup *sync.WaitGroup, httpClient httpclient.HTTPClient, imageOcr imageocr.ImageOcr) {
-	parser := LindyParser{
-		httpClient: httpClient,
-		imageOcr:   imageOcr,
+func newLindyParser(config Config, services Services) (Parser, error) {
+	if err := config.validate("menuImage"); err != nil {
+		return nil, err
 	}
 
-	defer func() {
-		if rec := recover(); rec == nil {
-			return
-		}
+	menuImgSelector, err := config.selector("menuImage")
+	if err != nil {
+		return nil, err
+	}
 
-		parser.log("Recovered from panic:\n%s", debug.Stack())
+	imageOcr := services.ImageOcr
 
-		menuChan <- Menu{
-			Restaurant: Lindy,
-			Meals:      nil,
+	if config.OCR != nil && config.OCR.Preprocess != nil {
+		if err := config.OCR.Preprocess.Validate(); err != nil {
+			return nil, err
 		}
-	}()
 
-	defer waitGroup.Done()
+		imageOcr = imageOcr.WithPipeline(config.OCR.Preprocess)
+	}
+
+	return LindyParser{
+		config:          config,
+		httpClient:      services.HTTPClient,
+		imageOcr:        imageOcr,
+		menuImgSelector: menuImgSelector,
+	}, nil
+}
 
-	meals, err := parser.parseMenu()
-	if err != nil {
-		parser.log("Err: %v", err)
+func (parser LindyParser) ID() string {
+	return parser.config.ID
+}
 
-		menuChan <- Menu{
-			Restaurant: Lindy,
-			Meals:      nil,
-		}
-		return
-	}
+func (parser LindyParser) Name() string {
+	return parser.config.Name
+}
 
-	menuChan <- Menu{
-		Restaurant: Lindy,
-		Meals:      meals,
+func (parser LindyParser) Parse(ctx context.Context) (Menu, error) {
+	menu := Menu{
+		SourceURL: parser.config.URL,
 	}
+
+	meals, err := parser.parseMenu(ctx, &menu)
+
+	classifyMeals(meals)
+
+	menu.Meals = meals
+
+	return menu, err
 }
 
-func (parser LindyParser) parseMenu() (*[]Meal, error) {
-	parser.log("Downloading HTML from URL \"%s\"", lindyURL)
+func (parser LindyParser) parseMenu(ctx context.Context, menu *Menu) ([]Meal, error) {
+	parser.log("Downloading HTML from URL \"%s\"", parser.config.URL)
 
-	htmlContent, err := parser.httpClient.DownloadHTML(lindyURL)
+	htmlContent, err := parser.httpClient.DownloadHTML(ctx, parser.config.URL)
 	if err != nil {
 		return nil, err
 	}
 
+	menu.FetchedAt = time.Now()
+
 	parser.log("Parsing HTML from a string with length %d", len(htmlContent))
 
 	rootNode, err := html.Parse(strings.NewReader(htmlContent))
@@ -76,71 +92,100 @@ func (parser LindyParser) parseMenu() (*[]Meal, error) {
 		return nil, err
 	}
 
-	menuImgSelector := css.MustParse("#DenneMenu img")
-
 	parser.log("Selecting daily menu image element with CSS selector")
 
-	menuImgEls := menuImgSelector.Select(rootNode)
+	menuImgEls := parser.menuImgSelector.Select(rootNode)
 	if len(menuImgEls) == 0 {
 		return nil, errors.New("daily menu image CSS selector didn't match any element")
 	}
 
-	parser.log("Selecting \"src\" attribute from the \"img\" daily menu element")
+	parser.log("Selecting \"src\" attributes from %d \"img\" daily menu elements", len(menuImgEls))
+
+	// The menu is sometimes split into several images, e.g. one per slide.
+	var imageURLs []string
+
+	for _, menuImgEl := range menuImgEls {
+		for _, imgAttribute := range menuImgEl.Attr {
+			if imgAttribute.Key != "src" || len(imgAttribute.Val) == 0 {
+				continue
+			}
+
+			imageURL, err := resolveURL(parser.config.URL, imgAttribute.Val)
+			if err != nil {
+				return nil, err
+			}
+
+			imageURLs = append(imageURLs, imageURL)
 
-	var imgSrc string
-	for _, imgAttribute := range menuImgEls[0].Attr {
-		if imgAttribute.Key == "src" {
-			imgSrc = imgAttribute.Val
 			break
 		}
 	}
 
-	if len(imgSrc) == 0 {
+	if len(imageURLs) == 0 {
 		return nil, errors.New("\"img\" daily menu element has no \"src\" attribute")
 	}
 
-	imageURL := lindyURL + imgSrc
+	var imgLines []imageocr.Line
 
-	parser.log("Downloading menu image from URL \"%s\"", imageURL)
+	for _, imageURL := range imageURLs {
+		parser.log("Downloading menu image from URL \"%s\"", imageURL)
 
-	imageContent, err := parser.httpClient.DownloadHTML(imageURL)
-	if err != nil {
-		return nil, err
-	}
+		imageRes, err := parser.httpClient.Download(ctx, imageURL)
+		if err != nil {
+			return nil, err
+		}
 
-	parser.log("Parsing text from image with length %d", len(imageContent))
+		parser.log("Parsing text lines from image with length %d", len(imageRes.Body))
 
-	imgText, err := parser.imageOcr.ParseJpegText([]byte(imageContent))
-	if err != nil {
+		urlLines, err := parser.imageOcr.ParseImageLines(ctx, imageRes.Body, imageRes.Header.Get("Content-Type"))
+		if err != nil {
+			return nil, err
+		}
+
+		imgLines = append(imgLines, urlLines...)
+	}
+
+	if err = checkClosed(parser.config, imageocr.LinesText(imgLines)); err != nil {
 		return nil, err
 	}
 
-	lines := strings.Split(imgText, "\n")
+	minLineConfidence := parser.config.OCR.thresholds().MinLineConfidence
+	lines := make([]imageocr.Line, 0, len(imgLines))
+
+	for _, imgLine := range imgLines {
+		if confidence := imgLine.Confidence(); confidence < minLineConfidence {
+			parser.log("Dropping line \"%s\" with confidence %.0f", imgLine.Text(), confidence)
+			continue
+		}
+
+		lines = append(lines, imgLine)
+	}
 
 	parser.log("Parsing %d lines into individual meals", len(lines))
 
 	meals := make([]Meal, 0)
+	mealConfidences := make([][]float64, 0)
 	var currMeal *Meal
 	var mealLength int
 
 	for _, line := range lines {
-		cleanedLine := strings.TrimSpace(line)
+		cleanedLine := strings.TrimSpace(line.Text())
 
 		if len(cleanedLine) == 0 {
 			continue
 		}
 
-		if strings.HasSuffix(cleanedLine, "€") {
-			name, price := parser.parseNamePrice(cleanedLine)
-
+		if name, price, ok := splitPrice(line); ok {
 			parser.log("Found first line for menu \"%s\"", name)
 
 			meals = append(meals, Meal{
 				Name:   name,
 				Price:  price,
-				Dishes: []string{},
+				Dishes: []Dish{},
 			})
 
+			mealConfidences = append(mealConfidences, []float64{line.Confidence()})
+
 			currMeal = &meals[len(meals)-1]
 
 			mealLength = 0
@@ -153,31 +198,77 @@ func (parser LindyParser) parseMenu() (*[]Meal, error) {
 		}
 
 		if currMeal != nil {
-			allergensRe := regexp.MustCompile(` [^ ]*\d[^ ]*$`)
-			cleanedLine = allergensRe.ReplaceAllString(cleanedLine, "")
+			currMeal.Dishes = append(currMeal.Dishes, newDish(cleanedLine))
 
-			currMeal.Dishes = append(currMeal.Dishes, cleanedLine)
+			mealConfidences[len(mealConfidences)-1] = append(mealConfidences[len(mealConfidences)-1], line.Confidence())
 
 			mealLength++
 		}
 	}
 
-	return &meals, nil
+	var lineConfidences []float64
+
+	for index := range meals {
+		meals[index].Confidence = mean(mealConfidences[index])
+
+		lineConfidences = append(lineConfidences, mealConfidences[index]...)
+	}
+
+	menu.OCR = assessOCR(parser.config.OCR, imageURLs, meals, lineConfidences)
+
+	if menu.OCR.Suspicious {
+		parser.log("OCR result looks suspicious: confidence %.0f, unknown words %.0f %%, prices %.0f %%",
+			menu.OCR.Confidence, 100*menu.OCR.UnknownWordShare, 100*menu.OCR.PriceRate)
+	}
+
+	return meals, nil
 }
 
-func (parser LindyParser) parseNamePrice(line string) (string, string) {
-	cleanedLine := strings.ReplaceAll(line, ".", ",")
-	namePriceRe := regexp.MustCompile(`(?U)(.+) (\d+,\d+)\s+€`)
+// centsPriceRe matches a price without the currency, which always has two decimals
+// unlike allergens like "1,7".
+var centsPriceRe = regexp.MustCompile(`^\d+[.,]\d{2}$`)
+
+// splitPrice pairs the name of a meal with the price on its row. The price is the
+// last cell of the line when there is a wide gap before it, otherwise the trailing
+// words ending with the currency, e.g. "6,90 €" or "6,90€". Allergens like "1,7"
+// look like prices too, also in a column of their own, so a price needs the currency
+// or two decimals in a cell and the currency within the text. A misread price is
+// kept as it was on the menu, so the meal isn't lost with it.
+func splitPrice(line imageocr.Line) (string, Price, bool) {
+	if cells := line.Cells(); len(cells) > 1 {
+		priceCell := cells[len(cells)-1]
+		priceText := strings.TrimSpace(priceCell.Text())
+
+		if endsWithCurrency(priceText) || centsPriceRe.MatchString(priceText) {
+			price := ParsePrice(priceText)
+			nameWords := line.Words[:len(line.Words)-len(priceCell.Words)]
+
+			return strings.TrimSpace(imageocr.Line{Words: nameWords}.Text()), price, true
+		}
+	}
 
-	match := namePriceRe.FindStringSubmatch(cleanedLine)
-	if match == nil {
-		return "", ""
+	words := line.Words
+	if len(words) < 2 || !endsWithCurrency(words[len(words)-1].Text) {
+		return "", Price{}, false
 	}
 
-	name := strings.TrimSpace(match[1])
-	price := match[2] + "€"
+	// The currency is often a word of its own, e.g. "6,90 €".
+	priceStart := len(words) - 1
+	if priceStart > 1 && isCurrency(words[priceStart].Text) {
+		priceStart--
+	}
+
+	price := ParsePrice(imageocr.Line{Words: words[priceStart:]}.Text())
+
+	return strings.TrimSpace(imageocr.Line{Words: words[:priceStart]}.Text()), price, true
+}
+
+func isCurrency(text string) bool {
+	return text == "€" || strings.EqualFold(text, "eur")
+}
 
-	return name, price
+func endsWithCurrency(text string) bool {
+	return strings.HasSuffix(text, "€") || strings.HasSuffix(strings.ToLower(text), "eur")
 }
 
 func (parser LindyParser) log(format string, v ...any) {
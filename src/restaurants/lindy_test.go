@@ -0,0 +1,57 @@
+package restaurants
+
+import (
+	"image"
+	"menucko/services/imageocr"
+	"strings"
+	"testing"
+)
+
+// ocrLine lays out the words of the text from the x position with one space
+// between them.
+func ocrLine(x int, text string) []imageocr.Word {
+	var words []imageocr.Word
+
+	for _, field := range strings.Fields(text) {
+		width := 20 * len([]rune(field))
+
+		words = append(words, imageocr.Word{Text: field, Box: image.Rect(x, 100, x+width, 130), Confidence: 90})
+		x += width + 20
+	}
+
+	return words
+}
+
+func TestSplitPrice(t *testing.T) {
+	tests := []struct {
+		words []imageocr.Word
+		name  string
+		cents int64
+		raw   string
+		ok    bool
+	}{
+		{ocrLine(40, "Menu 1 6,90 €"), "Menu 1", 690, "6,90 €", true},
+		{ocrLine(40, "Menu 1 5,90€"), "Menu 1", 590, "5,90€", true},
+		{ocrLine(40, "Menu 1 6.90 €"), "Menu 1", 690, "6.90 €", true},
+		{append(ocrLine(40, "Menu 2"), ocrLine(600, "7,40")...), "Menu 2", 740, "7,40", true},
+		{append(ocrLine(40, "Menu 3"), ocrLine(600, "8,20 €")...), "Menu 3", 820, "8,20 €", true},
+		{ocrLine(40, "Caesar šalát 8,90EUR"), "Caesar šalát", 890, "8,90EUR", true},
+		{ocrLine(40, "Menu 2 6,9O €"), "Menu 2", 0, "6,9O €", true},
+		{append(ocrLine(40, "Menu 3"), ocrLine(600, "8,2O€")...), "Menu 3", 0, "8,2O€", true},
+		{ocrLine(40, "Polievka: Brokolicová krémová 1,7"), "", 0, "", false},
+		{append(ocrLine(40, "Kuracie prsia"), ocrLine(600, "ryža")...), "", 0, "", false},
+		{append(ocrLine(40, "Kuracie prsia, ryža"), ocrLine(600, "1,7")...), "", 0, "", false},
+		{append(ocrLine(40, "Kuracie prsia, ryža"), ocrLine(600, "1")...), "", 0, "", false},
+		{ocrLine(40, "€"), "", 0, "", false},
+	}
+
+	for _, test := range tests {
+		line := imageocr.Line{Words: test.words}
+
+		name, price, ok := splitPrice(line)
+		if name != test.name || price.Cents != test.cents || price.Raw != test.raw || ok != test.ok {
+			t.Errorf("splitPrice(\"%s\") = \"%s\", %+v, %v, want \"%s\", %d cents, \"%s\", %v",
+				line.Text(), name, price, ok, test.name, test.cents, test.raw, test.ok)
+		}
+	}
+}
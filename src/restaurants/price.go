@@ -0,0 +1,87 @@
+package restaurants
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const CurrencyEUR = "EUR"
+
+// Price is an amount in cents. A price that couldn't be parsed keeps the text
+// from the menu in Raw and isn't Valid, so it can still be shown as it is.
+type Price struct {
+	Cents    int64
+	Currency string
+	Raw      string
+	Valid    bool
+}
+
+// Prices on menus look like "5.90", "5,90 €", "€ 5,9", "5,90EUR" or "5,-".
+var priceRe = regexp.MustCompile(`(?i)^(?:€|eur)?\s*(\d{1,6})(?:[.,](\d{1,2})|[.,]\s*-+)?\s*(?:€|eur)?$`)
+
+// ParsePrice parses the price from the menu, prices without a currency are in euros.
+func ParsePrice(text string) Price {
+	raw := strings.TrimSpace(text)
+
+	price := Price{Raw: raw}
+
+	match := priceRe.FindStringSubmatch(raw)
+	if match == nil {
+		return price
+	}
+
+	euros, err := strconv.ParseInt(match[1], 10, 64)
+	if err != nil {
+		return price
+	}
+
+	cents := int64(0)
+
+	if len(match[2]) != 0 {
+		cents, _ = strconv.ParseInt(match[2], 10, 64)
+
+		// "5,9" means 5 euros and 90 cents.
+		if len(match[2]) == 1 {
+			cents *= 10
+		}
+	}
+
+	price.Cents = euros*100 + cents
+	price.Currency = CurrencyEUR
+	price.Valid = true
+
+	return price
+}
+
+// IsZero reports whether the menu had no price at all.
+func (price Price) IsZero() bool {
+	return !price.Valid && len(price.Raw) == 0
+}
+
+// String formats the price for Slovak readers, e.g. "1 250,90 €" with non-breaking
+// spaces. Invalid prices are returned as they were on the menu.
+func (price Price) String() string {
+	if !price.Valid {
+		return price.Raw
+	}
+
+	euros := strconv.FormatInt(price.Cents/100, 10)
+
+	var grouped strings.Builder
+
+	for index, digit := range euros {
+		if index > 0 && (len(euros)-index)%3 == 0 {
+			grouped.WriteRune('\u00a0')
+		}
+
+		grouped.WriteRune(digit)
+	}
+
+	symbol := price.Currency
+	if symbol == CurrencyEUR {
+		symbol = "€"
+	}
+
+	return grouped.String() + "," + strconv.FormatInt(price.Cents%100+100, 10)[1:] + "\u00a0" + symbol
+}
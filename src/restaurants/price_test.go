@@ -0,0 +1,58 @@
+package restaurants
+
+import (
+	"testing"
+)
+
+func TestParsePrice(t *testing.T) {
+	tests := []struct {
+		text  string
+		cents int64
+		valid bool
+	}{
+		{"5.90", 590, true},
+		{"5,90 €", 590, true},
+		{"€ 5,9", 590, true},
+		{"5,90EUR", 590, true},
+		{"5,90 Eur", 590, true},
+		{" 12 € ", 1200, true},
+		{"7,-", 700, true},
+		{"6,9O€", 0, false},
+		{"Menu 5,90 €", 0, false},
+		{"", 0, false},
+	}
+
+	for _, test := range tests {
+		price := ParsePrice(test.text)
+
+		if price.Cents != test.cents || price.Valid != test.valid {
+			t.Errorf("ParsePrice(\"%s\") = %+v, want %d cents, valid %v", test.text, price, test.cents, test.valid)
+		}
+
+		if test.valid && price.Currency != CurrencyEUR {
+			t.Errorf("ParsePrice(\"%s\") has currency \"%s\"", test.text, price.Currency)
+		}
+	}
+}
+
+func TestPriceString(t *testing.T) {
+	tests := []struct {
+		price Price
+		want  string
+	}{
+		{ParsePrice("5.9"), "5,90\u00a0€"},
+		{ParsePrice("0,05 €"), "0,05\u00a0€"},
+		{Price{Cents: 125090, Currency: CurrencyEUR, Valid: true}, "1\u00a0250,90\u00a0€"},
+		{ParsePrice("cena dňa"), "cena dňa"},
+	}
+
+	for _, test := range tests {
+		if got := test.price.String(); got != test.want {
+			t.Errorf("got \"%s\", want \"%s\"", got, test.want)
+		}
+	}
+
+	if !ParsePrice(" ").IsZero() || ParsePrice("cena dňa").IsZero() {
+		t.Error("expected only a missing price to be zero")
+	}
+}
@@ -0,0 +1,168 @@
+package restaurants
+
+import (
+	_ "embed"
+	"menucko/services/imageocr"
+	"strings"
+	"unicode"
+)
+
+// OCRConfig sets the image preprocessing of a source and overrides the thresholds
+// below which a menu read from an image is flagged as suspicious. Zero values
+// fall back to the defaults.
+type OCRConfig struct {
+	Preprocess imageocr.Pipeline `json:"preprocess,omitempty"`
+	// MinLineConfidence is the Tesseract confidence below which a line is dropped
+	// as noise, e.g. the decorations around the menu.
+	MinLineConfidence float64 `json:"minLineConfidence,omitempty"`
+	MinConfidence     float64 `json:"minConfidence,omitempty"`
+	MaxUnknownShare   float64 `json:"maxUnknownShare,omitempty"`
+	MinPriceRate      float64 `json:"minPriceRate,omitempty"`
+}
+
+var defaultOCRConfig = OCRConfig{
+	MinLineConfidence: 40,
+	MinConfidence:     70,
+	MaxUnknownShare:   0.3,
+	MinPriceRate:      0.75,
+}
+
+// thresholds returns the configured thresholds with the defaults in place of zero
+// values, the config may be nil.
+func (config *OCRConfig) thresholds() OCRConfig {
+	thresholds := defaultOCRConfig
+
+	if config == nil {
+		return thresholds
+	}
+
+	if config.MinLineConfidence > 0 {
+		thresholds.MinLineConfidence = config.MinLineConfidence
+	}
+
+	if config.MinConfidence > 0 {
+		thresholds.MinConfidence = config.MinConfidence
+	}
+
+	if config.MaxUnknownShare > 0 {
+		thresholds.MaxUnknownShare = config.MaxUnknownShare
+	}
+
+	if config.MinPriceRate > 0 {
+		thresholds.MinPriceRate = config.MinPriceRate
+	}
+
+	return thresholds
+}
+
+// OCRQuality describes how trustworthy a menu read from an image is.
+type OCRQuality struct {
+	// ImageURLs are the images the menu was read from, in their order on the page.
+	ImageURLs []string
+	// Confidence is the mean Tesseract confidence of the lines the meals were read from.
+	Confidence float64
+	// UnknownWordShare is the share of dish words which aren't in the dictionary.
+	UnknownWordShare float64
+	// PriceRate is the share of meals with a price that could be parsed.
+	PriceRate  float64
+	Suspicious bool
+}
+
+//go:embed dictionary.txt
+var dictionaryContent string
+
+var dictionary = loadDictionary(dictionaryContent)
+
+// maxSuffixLength is the longest word ending dropped when looking a word up in the
+// dictionary, which is enough for Slovak declension.
+const maxSuffixLength = 3
+
+func loadDictionary(content string) map[string]bool {
+	words := make(map[string]bool)
+
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		words[strings.ToLower(line)] = true
+	}
+
+	return words
+}
+
+func isKnownWord(word string) bool {
+	runes := []rune(strings.ToLower(word))
+
+	for cut := 0; cut <= maxSuffixLength && cut < len(runes); cut++ {
+		if dictionary[string(runes[:len(runes)-cut])] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// assessOCR measures the quality of meals read from an image. Short words are
+// left out of the dictionary check, as prepositions and allergen numbers would
+// only dilute it.
+func assessOCR(config *OCRConfig, imageURLs []string, meals []Meal, lineConfidences []float64) *OCRQuality {
+	thresholds := config.thresholds()
+
+	quality := OCRQuality{
+		ImageURLs:  imageURLs,
+		Confidence: mean(lineConfidences),
+	}
+
+	var words, unknownWords, prices int
+
+	for _, meal := range meals {
+		if meal.Price.Valid {
+			prices++
+		}
+
+		for _, dish := range meal.Dishes {
+			for _, word := range strings.FieldsFunc(dish.Name, func(r rune) bool { return !unicode.IsLetter(r) }) {
+				if len([]rune(word)) < 3 {
+					continue
+				}
+
+				words++
+
+				if !isKnownWord(word) {
+					unknownWords++
+				}
+			}
+		}
+	}
+
+	if words > 0 {
+		quality.UnknownWordShare = float64(unknownWords) / float64(words)
+	}
+
+	if len(meals) > 0 {
+		quality.PriceRate = float64(prices) / float64(len(meals))
+	}
+
+	quality.Suspicious = quality.Confidence < thresholds.MinConfidence ||
+		quality.UnknownWordShare > thresholds.MaxUnknownShare ||
+		quality.PriceRate < thresholds.MinPriceRate
+
+	return &quality
+}
+
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	var sum float64
+
+	for _, value := range values {
+		sum += value
+	}
+
+	return sum / float64(len(values))
+}
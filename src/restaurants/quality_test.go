@@ -0,0 +1,54 @@
+package restaurants
+
+import (
+	"testing"
+)
+
+func TestAssessOCR(t *testing.T) {
+	goodMeals := []Meal{
+		{Name: "Menu 1", Price: ParsePrice("6,90€"), Dishes: []Dish{{Name: "Kuracie prsia na grile"}, {Name: "ryžové rezance, zeleninový šalát"}}},
+		{Name: "Menu 2", Price: ParsePrice("7,40 €"), Dishes: []Dish{{Name: "Bravčová krkovička na cesnaku"}, {Name: "opekané zemiaky"}}},
+	}
+
+	quality := assessOCR(nil, []string{"https://example.com/menu.jpg"}, goodMeals, []float64{91, 88, 90})
+	if quality.Suspicious || quality.UnknownWordShare != 0 || quality.PriceRate != 1 || quality.Confidence != 89.66666666666667 {
+		t.Errorf("expected trustworthy result, got %+v", *quality)
+	}
+
+	garbledMeals := []Meal{
+		{Name: "Menu 1", Price: ParsePrice("6,9O€"), Dishes: []Dish{{Name: "Kuraeie prsla ne grlle"}, {Name: "rvžové rezance"}}},
+		{Name: "Menu 2", Price: ParsePrice("7,40€"), Dishes: []Dish{{Name: "Bravčová krkovička"}}},
+	}
+
+	quality = assessOCR(nil, []string{"https://example.com/menu.jpg"}, garbledMeals, []float64{91, 88, 90})
+	if !quality.Suspicious || quality.PriceRate != 0.5 {
+		t.Errorf("expected suspicious result, got %+v", *quality)
+	}
+
+	quality = assessOCR(nil, []string{"https://example.com/menu-1.jpg", "https://example.com/menu-2.jpg"}, goodMeals, []float64{55, 60})
+	if !quality.Suspicious {
+		t.Errorf("expected low confidence to be suspicious, got %+v", *quality)
+	}
+
+	if len(quality.ImageURLs) != 2 {
+		t.Errorf("expected both images to be linked, got %+v", quality.ImageURLs)
+	}
+
+	quality = assessOCR(&OCRConfig{MinConfidence: 50}, nil, goodMeals, []float64{55, 60})
+	if quality.Suspicious {
+		t.Errorf("expected configured threshold to be used, got %+v", *quality)
+	}
+}
+
+func TestOCRThresholds(t *testing.T) {
+	var config *OCRConfig
+
+	if thresholds := config.thresholds(); thresholds.MinLineConfidence != 40 || thresholds.MinConfidence != 70 {
+		t.Errorf("nil config thresholds = %+v, want the defaults", thresholds)
+	}
+
+	thresholds := (&OCRConfig{MinLineConfidence: 55}).thresholds()
+	if thresholds.MinLineConfidence != 55 || thresholds.MinConfidence != defaultOCRConfig.MinConfidence {
+		t.Errorf("thresholds = %+v, want the configured line confidence and default rest", thresholds)
+	}
+}
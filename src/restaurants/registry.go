@@ -0,0 +1,57 @@
+package restaurants
+
+import (
+	"fmt"
+	"time"
+)
+
+type Registry struct {
+	parsers     []Parser
+	timeouts    map[string]time.Duration
+	closedDates map[string][]DateRange
+}
+
+// Register adds the parser to the registry. A zero timeout means the runner's default
+// restaurant timeout is used, closedDates are days the restaurant isn't scraped.
+func (registry *Registry) Register(parser Parser, timeout time.Duration, closedDates []DateRange) error {
+	for _, registered := range registry.parsers {
+		if registered.ID() == parser.ID() {
+			return fmt.Errorf("restaurant with ID \"%s\" is already registered", parser.ID())
+		}
+	}
+
+	registry.parsers = append(registry.parsers, parser)
+
+	if registry.timeouts == nil {
+		registry.timeouts = make(map[string]time.Duration)
+	}
+
+	registry.timeouts[parser.ID()] = timeout
+
+	if registry.closedDates == nil {
+		registry.closedDates = make(map[string][]DateRange)
+	}
+
+	registry.closedDates[parser.ID()] = closedDates
+
+	return nil
+}
+
+func (registry *Registry) Parsers() []Parser {
+	return registry.parsers
+}
+
+func (registry *Registry) Timeout(id string) time.Duration {
+	return registry.timeouts[id]
+}
+
+// ClosedOn reports whether the restaurant is closed on the date according to its config.
+func (registry *Registry) ClosedOn(id string, date time.Time) bool {
+	for _, dateRange := range registry.closedDates[id] {
+		if dateRange.contains(date) {
+			return true
+		}
+	}
+
+	return false
+}
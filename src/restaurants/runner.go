@@ -0,0 +1,290 @@
+package restaurants
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"menucko/services/clock"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+const runnerLogPrefix = "[Runner]"
+
+type Runner struct {
+	Registry *Registry
+	// Clock gives the day, for which the menus of daily parsers are stored.
+	Clock clock.Clock
+	// Timeout is the deadline for the whole run, zero means no deadline.
+	Timeout time.Duration
+	// RestaurantTimeout is used for restaurants without their own timeout, zero means no timeout.
+	RestaurantTimeout time.Duration
+}
+
+// Run runs all parsers concurrently. Parsers of restaurants publishing the whole week
+// fill in every day, the others only today. On weekends, public holidays and the
+// restaurant's closed dates a daily parser isn't run at all, a weekly one only when
+// the restaurant is closed on every date shown on the pages.
+func (runner Runner) Run(ctx context.Context) []WeeklyMenu {
+	if runner.Timeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, runner.Timeout)
+		defer cancel()
+	}
+
+	parsers := runner.Registry.Parsers()
+	today := clock.Today(runner.Clock)
+
+	if !clock.IsWorkday(today) {
+		runner.log("Running only weekly parsers, %s is not a working day", today.Format(DateLayout))
+	} else {
+		runner.log("Running %d parsers", len(parsers))
+	}
+
+	weeks := make([]WeeklyMenu, len(parsers))
+
+	waitGroup := sync.WaitGroup{}
+
+	for index, parser := range parsers {
+		waitGroup.Add(1)
+
+		go func(index int, parser Parser) {
+			defer waitGroup.Done()
+
+			if reason := runner.skipReason(parser, today); len(reason) != 0 {
+				weeks[index] = runner.markClosedDays(parser, runner.dailyWeek(parser, runner.closedMenu(parser, reason)), today)
+				return
+			}
+
+			weeks[index] = runner.markClosedDays(parser, runner.runParser(ctx, parser), today)
+		}(index, parser)
+	}
+
+	waitGroup.Wait()
+
+	return weeks
+}
+
+// runParser returns as soon as the parser's context is done, even when the parser
+// itself doesn't respect the context. The abandoned parser finishes in the background.
+func (runner Runner) runParser(ctx context.Context, parser Parser) WeeklyMenu {
+	timeout := runner.Registry.Timeout(parser.ID())
+	if timeout == 0 {
+		timeout = runner.RestaurantTimeout
+	}
+
+	if timeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	weekChan := make(chan WeeklyMenu, 1)
+
+	go func() {
+		weekChan <- runner.parse(ctx, parser)
+	}()
+
+	select {
+	case week := <-weekChan:
+		return week
+	case <-ctx.Done():
+		runner.log("Parser \"%s\" didn't finish in time, Err: %v", parser.ID(), ctx.Err())
+
+		return runner.failedWeek(parser, ErrorCategoryTimeout, ctx.Err().Error())
+	}
+}
+
+func (runner Runner) parse(ctx context.Context, parser Parser) (week WeeklyMenu) {
+	defer func() {
+		rec := recover()
+		if rec == nil {
+			return
+		}
+
+		runner.log("Parser \"%s\" recovered from panic:\n%s", parser.ID(), debug.Stack())
+
+		week = runner.failedWeek(parser, ErrorCategoryPanic, fmt.Sprint(rec))
+	}()
+
+	if weeklyParser, ok := parser.(WeeklyParser); ok {
+		return runner.parseWeek(ctx, weeklyParser)
+	}
+
+	return runner.dailyWeek(parser, runner.parseDay(ctx, parser))
+}
+
+func (runner Runner) parseWeek(ctx context.Context, parser WeeklyParser) WeeklyMenu {
+	week, err := parser.ParseWeek(ctx)
+
+	week.ID = parser.ID()
+	week.Name = parser.Name()
+	week.ParsedAt = time.Now()
+	week.Status, week.ErrorCategory = statusFromError(err)
+
+	if err != nil {
+		runner.log("Parser \"%s\" finished with status \"%s\", Err: %v", parser.ID(), week.Status, err)
+
+		week.Error = err.Error()
+		week.Days = nil
+
+		return week
+	}
+
+	runner.log("Parser \"%s\" found menus for %d days", parser.ID(), len(week.Days))
+
+	return week
+}
+
+func (runner Runner) parseDay(ctx context.Context, parser Parser) Menu {
+	menu, err := parser.Parse(ctx)
+
+	menu.ID = parser.ID()
+	menu.Name = parser.Name()
+	menu.ParsedAt = time.Now()
+	menu.Status, menu.ErrorCategory = statusFromError(err)
+
+	if err != nil {
+		runner.log("Parser \"%s\" finished with status \"%s\", Err: %v", parser.ID(), menu.Status, err)
+
+		menu.Error = err.Error()
+		menu.Meals = nil
+
+		return menu
+	}
+
+	if len(menu.Meals) == 0 {
+		runner.log("Parser \"%s\" found no meals", parser.ID())
+
+		menu.Status = StatusNotPublished
+	}
+
+	return menu
+}
+
+// dailyWeek stores the menu of a daily parser under today's date.
+func (runner Runner) dailyWeek(parser Parser, menu Menu) WeeklyMenu {
+	return WeeklyMenu{
+		ID:        parser.ID(),
+		Name:      parser.Name(),
+		Status:    StatusOK,
+		SourceURL: menu.SourceURL,
+		FetchedAt: menu.FetchedAt,
+		ParsedAt:  menu.ParsedAt,
+		Days: map[string]Menu{
+			clock.Today(runner.Clock).Format(DateLayout): menu,
+		},
+	}
+}
+
+// skipReason explains why the parser isn't run today, it's empty when it has to be.
+// A weekly parser also fills in the other days of the week and the next working day,
+// so it's skipped only when the restaurant is closed on all of them.
+func (runner Runner) skipReason(parser Parser, today time.Time) string {
+	reason := runner.closedReason(parser, today)
+	if len(reason) == 0 {
+		return ""
+	}
+
+	if _, ok := parser.(WeeklyParser); !ok {
+		return reason
+	}
+
+	for _, date := range pageDates(today) {
+		if len(runner.closedReason(parser, date)) == 0 {
+			return ""
+		}
+	}
+
+	return reason
+}
+
+// closedReason explains why the restaurant is closed on the date, it's empty when
+// the restaurant is open.
+func (runner Runner) closedReason(parser Parser, date time.Time) string {
+	if holiday := clock.Holiday(date); len(holiday) != 0 {
+		return "public holiday " + holiday
+	}
+
+	if clock.IsWeekend(date) {
+		return "weekend"
+	}
+
+	if runner.Registry.ClosedOn(parser.ID(), date) {
+		return "closed according to the config"
+	}
+
+	return ""
+}
+
+func (runner Runner) closedMenu(parser Parser, reason string) Menu {
+	return Menu{
+		ID:       parser.ID(),
+		Name:     parser.Name(),
+		Status:   StatusClosed,
+		Error:    reason,
+		ParsedAt: time.Now(),
+	}
+}
+
+// markClosedDays replaces the menus of the days of this week and of the next working
+// day, on which the restaurant is closed.
+func (runner Runner) markClosedDays(parser Parser, week WeeklyMenu, today time.Time) WeeklyMenu {
+	if week.Status != StatusOK {
+		return week
+	}
+
+	if week.Days == nil {
+		week.Days = make(map[string]Menu)
+	}
+
+	for _, date := range pageDates(today) {
+		if reason := runner.closedReason(parser, date); len(reason) != 0 {
+			week.Days[date.Format(DateLayout)] = runner.closedMenu(parser, reason)
+		}
+	}
+
+	return week
+}
+
+// pageDates are the dates shown on the rendered pages, the days of this week and
+// the next working day.
+func pageDates(today time.Time) []time.Time {
+	return append(WeekDates(today), NextWorkday(today))
+}
+
+// failedWeek is the result of a parser which didn't return. A daily parser failed
+// only today, a weekly one failed for the whole week.
+func (runner Runner) failedWeek(parser Parser, category ErrorCategory, message string) WeeklyMenu {
+	menu := Menu{
+		ID:            parser.ID(),
+		Name:          parser.Name(),
+		Status:        StatusFailed,
+		ErrorCategory: category,
+		Error:         message,
+		ParsedAt:      time.Now(),
+		Meals:         nil,
+	}
+
+	if _, ok := parser.(WeeklyParser); ok {
+		return WeeklyMenu{
+			ID:            menu.ID,
+			Name:          menu.Name,
+			Status:        menu.Status,
+			ErrorCategory: menu.ErrorCategory,
+			Error:         menu.Error,
+			ParsedAt:      menu.ParsedAt,
+		}
+	}
+
+	return runner.dailyWeek(parser, menu)
+}
+
+func (Runner) log(format string, v ...any) {
+	message := runnerLogPrefix + " " + fmt.Sprintf(format, v...)
+
+	log.Println(message)
+}
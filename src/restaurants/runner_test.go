@@ -0,0 +1,111 @@
+package restaurants
+
+import (
+	"context"
+	"menucko/services/clock"
+	"testing"
+	"time"
+)
+
+type stubParser struct {
+	id string
+	// block makes the parser wait until its context is done, ignoreContext makes it
+	// wait until the test ends.
+	block         bool
+	ignoreContext bool
+	done          chan struct{}
+}
+
+func (parser stubParser) ID() string {
+	return parser.id
+}
+
+func (parser stubParser) Name() string {
+	return parser.id
+}
+
+func (parser stubParser) Parse(ctx context.Context) (Menu, error) {
+	switch {
+	case parser.ignoreContext:
+		<-parser.done
+		return Menu{}, nil
+	case parser.block:
+		<-ctx.Done()
+		return Menu{}, ctx.Err()
+	}
+
+	return Menu{Meals: []Meal{{Name: "Menu 1"}}}, nil
+}
+
+func runStubs(t *testing.T, runner Runner, timeouts map[string]time.Duration, parsers ...stubParser) map[string]Menu {
+	t.Helper()
+
+	registry := &Registry{}
+
+	for _, parser := range parsers {
+		if err := registry.Register(parser, timeouts[parser.id], nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	runner.Registry = registry
+	runner.Clock = clock.DevClock{Frozen: aprilDate(16)}
+
+	start := time.Now()
+	weeks := runner.Run(context.Background())
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("run took %v, the deadline wasn't respected", elapsed)
+	}
+
+	menus := make(map[string]Menu)
+
+	for _, week := range weeks {
+		menus[week.ID] = week.Menu(aprilDate(16))
+	}
+
+	return menus
+}
+
+func TestRunnerRestaurantTimeout(t *testing.T) {
+	done := make(chan struct{})
+	defer close(done)
+
+	menus := runStubs(t,
+		Runner{RestaurantTimeout: time.Minute},
+		map[string]time.Duration{"blocking": 20 * time.Millisecond, "stuck": 20 * time.Millisecond},
+		stubParser{id: "blocking", block: true},
+		stubParser{id: "stuck", ignoreContext: true, done: done},
+		stubParser{id: "fast"},
+	)
+
+	for _, id := range []string{"blocking", "stuck"} {
+		if menus[id].Status != StatusFailed || menus[id].ErrorCategory != ErrorCategoryTimeout {
+			t.Errorf("parser \"%s\" has status \"%s\" and category \"%s\", want a timeout", id, menus[id].Status, menus[id].ErrorCategory)
+		}
+	}
+
+	if menus["fast"].Status != StatusOK || len(menus["fast"].Meals) != 1 {
+		t.Errorf("fast parser = %+v, want its meals", menus["fast"])
+	}
+}
+
+func TestRunnerRunTimeout(t *testing.T) {
+	done := make(chan struct{})
+	defer close(done)
+
+	menus := runStubs(t,
+		Runner{Timeout: 20 * time.Millisecond},
+		nil,
+		stubParser{id: "stuck", ignoreContext: true, done: done},
+		stubParser{id: "fast"},
+	)
+
+	if menus["stuck"].Status != StatusFailed || menus["stuck"].ErrorCategory != ErrorCategoryTimeout {
+		t.Errorf("stuck parser has status \"%s\" and category \"%s\", want a timeout", menus["stuck"].Status, menus["stuck"].ErrorCategory)
+	}
+
+	if menus["fast"].Status != StatusOK || len(menus["fast"].Meals) != 1 {
+		t.Errorf("fast parser = %+v, want its meals", menus["fast"])
+	}
+}
@@ -0,0 +1,124 @@
+package restaurants
+
+import (
+	"context"
+	"menucko/services/clock"
+	"time"
+)
+
+// DateLayout formats the keys of WeeklyMenu.Days.
+const DateLayout = "2006-01-02"
+
+// WeeklyParser is implemented by parsers of restaurants which publish the menus
+// of the whole week at once, so they are downloaded only once.
+type WeeklyParser interface {
+	Parser
+	ParseWeek(ctx context.Context) (WeeklyMenu, error)
+}
+
+// WeeklyMenu holds the menus a restaurant published, keyed by their date. Status
+// and Error describe the week as a whole, e.g. when the page couldn't be downloaded.
+type WeeklyMenu struct {
+	ID            string
+	Name          string
+	Status        Status
+	ErrorCategory ErrorCategory
+	Error         string
+	SourceURL     string
+	FetchedAt     time.Time
+	ParsedAt      time.Time
+	Days          map[string]Menu
+}
+
+// Menu returns the menu for the date. A failed week gives a failed menu for every
+// date, and a date the restaurant didn't publish gives a not published menu.
+func (week WeeklyMenu) Menu(date time.Time) Menu {
+	menu, ok := week.Days[date.Format(DateLayout)]
+
+	switch {
+	case week.Status != StatusOK && len(week.Status) != 0:
+		menu = Menu{
+			Status:        week.Status,
+			ErrorCategory: week.ErrorCategory,
+			Error:         week.Error,
+		}
+	case !ok:
+		menu = Menu{Status: StatusNotPublished}
+	}
+
+	menu.ID = week.ID
+	menu.Name = week.Name
+
+	if len(menu.SourceURL) == 0 {
+		menu.SourceURL = week.SourceURL
+	}
+
+	if menu.FetchedAt.IsZero() {
+		menu.FetchedAt = week.FetchedAt
+	}
+
+	if menu.ParsedAt.IsZero() {
+		menu.ParsedAt = week.ParsedAt
+	}
+
+	return menu
+}
+
+// MenusForDate picks the menu of every restaurant for the date.
+func MenusForDate(weeks []WeeklyMenu, date time.Time) []Menu {
+	menus := make([]Menu, 0, len(weeks))
+
+	for _, week := range weeks {
+		menus = append(menus, week.Menu(date))
+	}
+
+	return menus
+}
+
+// WeekDates returns the working days of the week the date is in.
+func WeekDates(date time.Time) []time.Time {
+	monday := date.AddDate(0, 0, -clock.Weekday(date))
+
+	dates := make([]time.Time, 0, 5)
+
+	for index := 0; index < 5; index++ {
+		dates = append(dates, monday.AddDate(0, 0, index))
+	}
+
+	return dates
+}
+
+// NextWorkday returns the day after the date, skipping weekends and public holidays.
+func NextWorkday(date time.Time) time.Time {
+	next := date.AddDate(0, 0, 1)
+
+	for !clock.IsWorkday(next) {
+		next = next.AddDate(0, 0, 1)
+	}
+
+	return next
+}
+
+// dayMenu builds the menu of one day of a weekly menu, the status comes from the
+// error the same way the runner sets it for daily menus.
+func dayMenu(meals []Meal, err error) Menu {
+	menu := Menu{}
+
+	menu.Status, menu.ErrorCategory = statusFromError(err)
+
+	if err != nil {
+		menu.Error = err.Error()
+
+		return menu
+	}
+
+	classifyMeals(meals)
+
+	menu.Meals = meals
+
+	if len(meals) == 0 {
+		menu.Status = StatusNotPublished
+	}
+
+	return menu
+}
@@ -0,0 +1,67 @@
+package restaurants
+
+import (
+	"testing"
+	"time"
+)
+
+func aprilDate(day int) time.Time {
+	return time.Date(2024, 4, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestWeekDates(t *testing.T) {
+	for _, day := range []int{15, 17, 19, 21} {
+		dates := WeekDates(aprilDate(day))
+
+		if len(dates) != 5 || !dates[0].Equal(aprilDate(15)) || !dates[4].Equal(aprilDate(19)) {
+			t.Errorf("WeekDates(%d.4.) = %v, want 15.4. to 19.4.", day, dates)
+		}
+	}
+}
+
+func TestNextWorkday(t *testing.T) {
+	tests := []struct {
+		day  int
+		want int
+	}{
+		{15, 16},
+		{18, 19},
+		{19, 22},
+		{20, 22},
+		{21, 22},
+	}
+
+	for _, test := range tests {
+		if got := NextWorkday(aprilDate(test.day)); !got.Equal(aprilDate(test.want)) {
+			t.Errorf("NextWorkday(%d.4.) = %v, want %d.4.", test.day, got, test.want)
+		}
+	}
+}
+
+func TestWeeklyMenuMenu(t *testing.T) {
+	week := WeeklyMenu{
+		ID:        "pizza",
+		Name:      "Pizza",
+		Status:    StatusOK,
+		SourceURL: "https://example.com",
+		Days: map[string]Menu{
+			"2024-04-15": {Status: StatusOK, Meals: []Meal{{Name: "Menu 1"}}},
+		},
+	}
+
+	menu := week.Menu(aprilDate(15))
+	if menu.Status != StatusOK || len(menu.Meals) != 1 || menu.ID != "pizza" || menu.SourceURL != week.SourceURL {
+		t.Errorf("menu of a published day = %+v", menu)
+	}
+
+	if menu = week.Menu(aprilDate(16)); menu.Status != StatusNotPublished || menu.Name != "Pizza" {
+		t.Errorf("menu of a missing day = %+v", menu)
+	}
+
+	week.Status = StatusFailed
+	week.ErrorCategory = ErrorCategoryTimeout
+
+	if menu = week.Menu(aprilDate(15)); menu.Status != StatusFailed || menu.ErrorCategory != ErrorCategoryTimeout || len(menu.Meals) != 0 {
+		t.Errorf("menu of a failed week = %+v", menu)
+	}
+}
@@ -1,92 +1,242 @@
 package main
 
 import (
+	"errors"
 	"fmt"
-	"menucko/services/dateresolver"
+	"menucko/config"
+	"menucko/restaurants"
+	"menucko/services/clock"
 	"menucko/services/distributor"
+	"menucko/services/httpclient"
+	"menucko/services/imageocr"
+	"menucko/services/pdftext"
 	"menucko/services/renderer"
+	"menucko/services/resultcache"
 	"os"
 	"strconv"
 )
 
+const configPathEnv = "MENUCKO_CONFIG"
+const defaultConfigPath = "config/menucko.json"
+const nowEnv = "MENUCKO_NOW"
 const weekdayEnv = "MENUCKO_WEEKDAY"
-const htmlTemplateEnv = "MENUCKO_HTML_TEMPLATE"
-const stylesPathEnv = "MENUCKO_STYLES_PATH"
-const commitHashEnv = "MENUCKO_COMMIT_HASH"
-const blobConnStrEnv = "MENUCKO_BLOB_CONN_STR"
-const blobContNameEnv = "MENUCKO_BLOB_CONT_NAME"
-const blobNameEnv = "MENUCKO_BLOB_NAME"
-
-func getDateResolver() (dateresolver.DateResolver, error) {
+
+func getConfig() (config.Config, error) {
+	configPath := os.Getenv(configPathEnv)
+	if len(configPath) == 0 {
+		configPath = defaultConfigPath
+	}
+
+	return config.Load(configPath)
+}
+
+// getClock freezes the time at "MENUCKO_NOW", e.g. "2024-04-16 11:30". "MENUCKO_WEEKDAY"
+// moves it to the day with the index, starting with 0 for Monday, of the same week.
+func getClock(conf config.Config) (clock.Clock, error) {
+	location, err := conf.Clock.Location()
+	if err != nil {
+		return nil, err
+	}
+
+	prodClock := clock.ProdClock{Location: location}
+
+	staticNowStr := os.Getenv(nowEnv)
 	staticWeekdayStr := os.Getenv(weekdayEnv)
 
-	if len(staticWeekdayStr) == 0 {
-		return dateresolver.ProdDateResolver{}, nil
+	if len(staticNowStr) == 0 && len(staticWeekdayStr) == 0 {
+		return prodClock, nil
 	}
 
-	staticWeekday, err := strconv.Atoi(staticWeekdayStr)
+	now := prodClock.Now()
+
+	if len(staticNowStr) != 0 {
+		now, err = clock.ParseFrozen(staticNowStr, location)
+		if err != nil {
+			return nil, fmt.Errorf("env \"%s\" is not valid: %w", nowEnv, err)
+		}
+	}
+
+	if len(staticWeekdayStr) != 0 {
+		staticWeekday, err := strconv.Atoi(staticWeekdayStr)
+		if err != nil || staticWeekday < 0 || staticWeekday > 6 {
+			return nil, fmt.Errorf("env \"%s\" with value \"%s\" is not a valid weekday", weekdayEnv, staticWeekdayStr)
+		}
+
+		now = now.AddDate(0, 0, staticWeekday-clock.Weekday(now))
+	}
+
+	return clock.DevClock{Frozen: now}, nil
+}
+
+func getHTTPClient(conf config.Config) (httpclient.HTTPClient, error) {
+	if len(conf.HTTP.ReplayDir) != 0 {
+		replayClient, err := httpclient.NewReplayHTTPClient(conf.HTTP.ReplayDir)
+		if err != nil {
+			return nil, err
+		}
+
+		return replayClient, nil
+	}
+
+	httpClient, err := getNetworkHTTPClient(conf)
 	if err != nil {
-		return nil, fmt.Errorf("env \"%s\" with value \"%s\" is not a valid number", weekdayEnv, staticWeekdayStr)
+		return nil, err
 	}
 
-	return dateresolver.DevDateResolver{WeekdayVal: staticWeekday}, nil
+	if len(conf.HTTP.RecordDir) != 0 {
+		recordingClient, err := httpclient.NewRecordingHTTPClient(httpClient, conf.HTTP.RecordDir)
+		if err != nil {
+			return nil, err
+		}
+
+		return recordingClient, nil
+	}
+
+	return httpClient, nil
 }
 
-func getRenderer() (renderer.Renderer, error) {
-	htmlTemplate := os.Getenv(htmlTemplateEnv)
-	if len(htmlTemplate) == 0 {
-		return nil, fmt.Errorf("env \"%s\" is empty", htmlTemplateEnv)
+func getNetworkHTTPClient(conf config.Config) (httpclient.HTTPClient, error) {
+	clientConfig, err := conf.HTTP.ClientConfig()
+	if err != nil {
+		return nil, err
 	}
 
-	stylesPath := os.Getenv(stylesPathEnv)
-	if len(stylesPath) == 0 {
-		return nil, fmt.Errorf("env \"%s\" is empty", stylesPathEnv)
+	prodClient := httpclient.NewProdHTTPClient(clientConfig)
+
+	if len(conf.HTTP.Cache.Dir) == 0 {
+		if conf.HTTP.Cache.Offline {
+			return nil, errors.New("offline mode requires config key \"http.cache.dir\"")
+		}
+
+		return prodClient, nil
+	}
+
+	ttl, err := conf.HTTP.Cache.TTLDuration()
+	if err != nil {
+		return nil, err
 	}
 
-	commitHash := os.Getenv(commitHashEnv)
-	if len(stylesPath) == 0 {
-		return nil, fmt.Errorf("env \"%s\" is empty", commitHashEnv)
+	return httpclient.CachingHTTPClient{
+		Fetcher: prodClient,
+		Dir:     conf.HTTP.Cache.Dir,
+		TTL:     ttl,
+		Offline: conf.HTTP.Cache.Offline,
+	}, nil
+}
+
+func getResultCache(conf config.Config) (*resultcache.Cache, error) {
+	if len(conf.ResultCache.Dir) == 0 {
+		return nil, nil
 	}
 
-	dateResolver, err := getDateResolver()
+	maxAge, err := conf.ResultCache.MaxAgeDuration()
 	if err != nil {
 		return nil, err
 	}
 
-	return renderer.HTMLRenderer{
-		DateResolver:     dateResolver,
-		TemplateFilePath: htmlTemplate,
-		StylesPath:       stylesPath,
-		CommitHash:       commitHash,
+	return &resultcache.Cache{
+		Dir:     conf.ResultCache.Dir,
+		MaxAge:  maxAge,
+		MaxSize: conf.ResultCache.MaxSize,
 	}, nil
 }
 
-func getDistributor() (distributor.Distributor, error) {
-	blobConnStr := os.Getenv(blobConnStrEnv)
-	if len(blobConnStr) == 0 {
-		return nil, fmt.Errorf("env \"%s\" is empty", blobConnStrEnv)
+func getImageOcr(cache *resultcache.Cache) imageocr.ImageOcr {
+	if cache == nil {
+		return imageocr.ProdImageOcr{}
+	}
+
+	return imageocr.CachingImageOcr{
+		ImageOcr: imageocr.ProdImageOcr{},
+		Cache:    *cache,
+	}
+}
+
+func getPDFText(cache *resultcache.Cache) pdftext.PDFText {
+	if cache == nil {
+		return pdftext.ProdPDFText{}
+	}
+
+	return pdftext.CachingPDFText{
+		PDFText: pdftext.ProdPDFText{},
+		Cache:   *cache,
+	}
+}
+
+func getRegistry(conf config.Config, services restaurants.Services) (*restaurants.Registry, error) {
+	if len(conf.Restaurants) == 0 {
+		return nil, errors.New("config has no restaurants")
 	}
 
-	blobContName := os.Getenv(blobContNameEnv)
-	if len(blobContName) == 0 {
-		return nil, fmt.Errorf("env \"%s\" is empty", blobContNameEnv)
+	return restaurants.NewRegistry(conf.Restaurants, services)
+}
+
+func getRunner(conf config.Config, registry *restaurants.Registry, clock clock.Clock) (restaurants.Runner, error) {
+	timeout, restaurantTimeout, err := conf.Runner.Timeouts()
+	if err != nil {
+		return restaurants.Runner{}, err
 	}
 
-	blobName := os.Getenv(blobNameEnv)
-	if len(blobName) == 0 {
-		return nil, fmt.Errorf("env \"%s\" is empty", blobNameEnv)
+	return restaurants.Runner{
+		Registry:          registry,
+		Clock:             clock,
+		Timeout:           timeout,
+		RestaurantTimeout: restaurantTimeout,
+	}, nil
+}
+
+// getFileNames returns the blob names of the rendered pages.
+func getFileNames(conf config.Config) (map[renderer.Page]string, error) {
+	if len(conf.Distributor.BlobName) == 0 {
+		return nil, errors.New("config key \"distributor.blobName\" is empty")
 	}
 
-	if blobConnStr == "local" {
+	return map[renderer.Page]string{
+		renderer.PageToday:    conf.Distributor.BlobName,
+		renderer.PageTomorrow: conf.Distributor.TomorrowBlobName,
+		renderer.PageWeek:     conf.Distributor.WeekBlobName,
+	}, nil
+}
+
+func getRenderer(conf config.Config, clock clock.Clock, fileNames map[renderer.Page]string) (renderer.Renderer, error) {
+	if len(conf.Renderer.TemplatePath) == 0 {
+		return nil, errors.New("config key \"renderer.templatePath\" is empty")
+	}
+
+	if len(conf.Renderer.StylesPath) == 0 {
+		return nil, errors.New("config key \"renderer.stylesPath\" is empty")
+	}
+
+	return renderer.HTMLRenderer{
+		Clock:            clock,
+		TemplateFilePath: conf.Renderer.TemplatePath,
+		StylesPath:       conf.Renderer.StylesPath,
+		CommitHash:       conf.Renderer.CommitHash,
+		FileNames:        fileNames,
+	}, nil
+}
+
+func getDistributor(conf config.Config) (distributor.Distributor, error) {
+	if len(conf.Distributor.Container) == 0 {
+		return nil, errors.New("config key \"distributor.container\" is empty")
+	}
+
+	switch conf.Distributor.Type {
+	case config.LocalDistributor:
 		return distributor.LocalDistributor{
-			Directory: blobContName,
-			FileName:  blobName,
+			Directory: conf.Distributor.Container,
+		}, nil
+
+	case config.AzureDistributor:
+		if len(conf.Distributor.ConnectionString) == 0 {
+			return nil, errors.New("config key \"distributor.connectionString\" is empty")
+		}
+
+		return distributor.AzureDistributor{
+			BlobConnStr:   conf.Distributor.ConnectionString,
+			ContainerName: conf.Distributor.Container,
 		}, nil
 	}
 
-	return distributor.AzureDistributor{
-		BlobConnStr:   blobConnStr,
-		ContainerName: blobContName,
-		BlobName:      blobName,
-	}, nil
+	return nil, fmt.Errorf("config key \"distributor.type\" has unknown value \"%s\"", conf.Distributor.Type)
 }
@@ -0,0 +1,92 @@
+package clock
+
+import (
+	"fmt"
+	"time"
+	_ "time/tzdata"
+)
+
+// Clock is the single source of the current time, so the parsers and the renderer
+// agree on which day it is.
+type Clock interface {
+	// Now returns the current time in the configured time zone.
+	Now() time.Time
+}
+
+type ProdClock struct {
+	Location *time.Location
+}
+
+func (clock ProdClock) Now() time.Time {
+	return time.Now().In(clock.Location)
+}
+
+// DevClock always returns Frozen, so the menus can be parsed as if it was any
+// other day.
+type DevClock struct {
+	Frozen time.Time
+}
+
+func (clock DevClock) Now() time.Time {
+	return clock.Frozen
+}
+
+// Today returns the midnight starting the current day in the clock's time zone.
+func Today(clock Clock) time.Time {
+	return Date(clock.Now())
+}
+
+// Date returns the midnight starting the day of t in its time zone.
+func Date(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// Weekday returns the day of the week of t starting with 0 for Monday.
+func Weekday(t time.Time) int {
+	return (int(t.Weekday()) + 6) % 7
+}
+
+// ISOWeek returns the number of the ISO 8601 week of t.
+func ISOWeek(t time.Time) int {
+	_, week := t.ISOWeek()
+
+	return week
+}
+
+var dayNames = [...]string{"Pondelok", "Utorok", "Streda", "Štvrtok", "Piatok", "Sobota", "Nedeľa"}
+
+// Month names in the nominative, e.g. "apríl", and in the genitive used in dates,
+// e.g. "16. apríla".
+var monthNames = [...]string{"január", "február", "marec", "apríl", "máj", "jún", "júl", "august", "september", "október", "november", "december"}
+var monthNamesGenitive = [...]string{"januára", "februára", "marca", "apríla", "mája", "júna", "júla", "augusta", "septembra", "októbra", "novembra", "decembra"}
+
+// DayName returns the Slovak name of the day of the week of t, e.g. "Pondelok".
+func DayName(t time.Time) string {
+	return dayNames[Weekday(t)]
+}
+
+// MonthName returns the Slovak name of the month of t, e.g. "apríl".
+func MonthName(t time.Time) string {
+	return monthNames[t.Month()-1]
+}
+
+// FormatDate formats the day and month of t the Slovak way, e.g. "16. apríla".
+func FormatDate(t time.Time) string {
+	return fmt.Sprintf("%d. %s", t.Day(), monthNamesGenitive[t.Month()-1])
+}
+
+// ParseFrozen reads the time a DevClock is frozen at, either a full RFC 3339 time
+// or a date with an optional time of the day in the location.
+func ParseFrozen(value string, location *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.In(location), nil
+	}
+
+	for _, layout := range []string{"2006-01-02 15:04", "2006-01-02T15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, value, location); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("time \"%s\" is neither RFC 3339 nor \"YYYY-MM-DD[ HH:MM]\"", value)
+}
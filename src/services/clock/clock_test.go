@@ -0,0 +1,131 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTodayInLocation(t *testing.T) {
+	location, err := time.LoadLocation("Europe/Bratislava")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 22:30 UTC on Monday is already Tuesday in Bratislava.
+	clock := DevClock{Frozen: time.Date(2024, 4, 15, 22, 30, 0, 0, time.UTC).In(location)}
+
+	today := Today(clock)
+
+	if today.Day() != 16 || today.Hour() != 0 || Weekday(today) != 1 || DayName(today) != "Utorok" {
+		t.Errorf("Today() = %v, want Tuesday 16.4. at midnight", today)
+	}
+}
+
+func TestNames(t *testing.T) {
+	date := time.Date(2024, 4, 21, 12, 0, 0, 0, time.UTC)
+
+	if got := DayName(date); got != "Nedeľa" {
+		t.Errorf("DayName() = \"%s\"", got)
+	}
+
+	if got := MonthName(date); got != "apríl" {
+		t.Errorf("MonthName() = \"%s\"", got)
+	}
+
+	if got := FormatDate(date); got != "21. apríla" {
+		t.Errorf("FormatDate() = \"%s\"", got)
+	}
+
+	if got := ISOWeek(date); got != 16 {
+		t.Errorf("ISOWeek() = %d", got)
+	}
+}
+
+func TestParseFrozen(t *testing.T) {
+	location, err := time.LoadLocation("Europe/Bratislava")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		value string
+		want  time.Time
+	}{
+		{"2024-04-16", time.Date(2024, 4, 16, 0, 0, 0, 0, location)},
+		{"2024-04-16 11:30", time.Date(2024, 4, 16, 11, 30, 0, 0, location)},
+		{"2024-04-16T11:30", time.Date(2024, 4, 16, 11, 30, 0, 0, location)},
+		{"2024-04-16T09:30:00Z", time.Date(2024, 4, 16, 11, 30, 0, 0, location)},
+	}
+
+	for _, test := range tests {
+		got, err := ParseFrozen(test.value, location)
+		if err != nil || !got.Equal(test.want) || got.Location() != location {
+			t.Errorf("ParseFrozen(\"%s\") = %v, %v, want %v", test.value, got, err, test.want)
+		}
+	}
+
+	if _, err := ParseFrozen("utorok", location); err == nil {
+		t.Error("ParseFrozen(\"utorok\") didn't fail")
+	}
+}
+
+func TestEaster(t *testing.T) {
+	tests := map[int]string{
+		2019: "2019-04-21",
+		2024: "2024-03-31",
+		2025: "2025-04-20",
+		2038: "2038-04-25",
+	}
+
+	for year, want := range tests {
+		if got := Easter(year).Format("2006-01-02"); got != want {
+			t.Errorf("Easter(%d) = %s, want %s", year, got, want)
+		}
+	}
+}
+
+func TestHoliday(t *testing.T) {
+	tests := []struct {
+		date string
+		want string
+	}{
+		{"2024-03-29", "Veľký piatok"},
+		{"2024-04-01", "Veľkonočný pondelok"},
+		{"2024-04-02", ""},
+		{"2024-09-01", "Deň Ústavy Slovenskej republiky"},
+		{"2025-09-01", ""},
+		{"2024-11-17", "Deň boja za slobodu a demokraciu"},
+		{"2025-12-24", "Štedrý deň"},
+	}
+
+	for _, test := range tests {
+		date, err := time.Parse("2006-01-02", test.date)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := Holiday(date); got != test.want {
+			t.Errorf("Holiday(%s) = \"%s\", want \"%s\"", test.date, got, test.want)
+		}
+	}
+}
+
+func TestIsWorkday(t *testing.T) {
+	tests := map[string]bool{
+		"2024-04-16": true,
+		"2024-04-20": false,
+		"2024-04-21": false,
+		"2024-05-01": false,
+	}
+
+	for value, want := range tests {
+		date, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := IsWorkday(date); got != want {
+			t.Errorf("IsWorkday(%s) = %v, want %v", value, got, want)
+		}
+	}
+}
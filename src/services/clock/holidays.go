@@ -0,0 +1,92 @@
+package clock
+
+import "time"
+
+type holiday struct {
+	month time.Month
+	day   int
+	name  string
+	// lastYear is the last year the day was a public holiday, zero means it still is.
+	lastYear int
+}
+
+// Slovak public holidays on a fixed date. Since 2025 the Constitution Day and the
+// Struggle for Freedom and Democracy Day are only memorial days.
+var fixedHolidays = []holiday{
+	{time.January, 1, "Deň vzniku Slovenskej republiky", 0},
+	{time.January, 6, "Zjavenie Pána", 0},
+	{time.May, 1, "Sviatok práce", 0},
+	{time.May, 8, "Deň víťazstva nad fašizmom", 0},
+	{time.July, 5, "Sviatok svätého Cyrila a svätého Metoda", 0},
+	{time.August, 29, "Výročie Slovenského národného povstania", 0},
+	{time.September, 1, "Deň Ústavy Slovenskej republiky", 2024},
+	{time.September, 15, "Sedembolestná Panna Mária", 0},
+	{time.November, 1, "Sviatok Všetkých svätých", 0},
+	{time.November, 17, "Deň boja za slobodu a demokraciu", 2024},
+	{time.December, 24, "Štedrý deň", 0},
+	{time.December, 25, "Prvý sviatok vianočný", 0},
+	{time.December, 26, "Druhý sviatok vianočný", 0},
+}
+
+// Holidays moving with Easter, as days from Easter Sunday.
+var easterHolidays = map[int]string{
+	-2: "Veľký piatok",
+	1:  "Veľkonočný pondelok",
+}
+
+// Holiday returns the name of the Slovak public holiday on the date, or an empty
+// string on other days.
+func Holiday(date time.Time) string {
+	for _, holiday := range fixedHolidays {
+		if date.Month() != holiday.month || date.Day() != holiday.day {
+			continue
+		}
+
+		if holiday.lastYear == 0 || date.Year() <= holiday.lastYear {
+			return holiday.name
+		}
+	}
+
+	easter := Easter(date.Year())
+
+	for offset, name := range easterHolidays {
+		day := easter.AddDate(0, 0, offset)
+
+		if date.Month() == day.Month() && date.Day() == day.Day() {
+			return name
+		}
+	}
+
+	return ""
+}
+
+// IsWeekend reports whether the date is a Saturday or a Sunday.
+func IsWeekend(date time.Time) bool {
+	return date.Weekday() == time.Saturday || date.Weekday() == time.Sunday
+}
+
+// IsWorkday reports whether the date is neither on a weekend nor a public holiday.
+func IsWorkday(date time.Time) bool {
+	return !IsWeekend(date) && len(Holiday(date)) == 0
+}
+
+// Easter returns the Easter Sunday of the year in the Gregorian calendar, computed
+// with the anonymous Gregorian algorithm.
+func Easter(year int) time.Time {
+	a := year % 19
+	b := year / 100
+	c := year % 100
+	d := b / 4
+	e := b % 4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i := c / 4
+	k := c % 4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+}
@@ -15,10 +15,9 @@ const azureDistributorLogPrefix = "[Azure Distributor]"
 type AzureDistributor struct {
 	BlobConnStr   string
 	ContainerName string
-	BlobName      string
 }
 
-func (d AzureDistributor) Distribute(content *bytes.Buffer) error {
+func (d AzureDistributor) Distribute(name string, content *bytes.Buffer) error {
 	d.log("Creation blob client using the connection string")
 	client, err := azblob.NewClientFromConnectionString(d.BlobConnStr, &azblob.ClientOptions{})
 	if err != nil {
@@ -42,10 +41,10 @@ func (d AzureDistributor) Distribute(content *bytes.Buffer) error {
 		}
 	}
 
-	d.log("Uploading content")
+	d.log("Uploading content to blob \"%s\"", name)
 	contentType := "text/html"
 
-	_, err = client.UploadStream(context.Background(), d.ContainerName, d.BlobName, content, &azblob.UploadStreamOptions{
+	_, err = client.UploadStream(context.Background(), d.ContainerName, name, content, &azblob.UploadStreamOptions{
 		HTTPHeaders: &blob.HTTPHeaders{
 			BlobContentType: &contentType,
 		},
@@ -3,5 +3,6 @@ package distributor
 import "bytes"
 
 type Distributor interface {
-	Distribute(content *bytes.Buffer) error
+	// Distribute publishes the content under the file name.
+	Distribute(name string, content *bytes.Buffer) error
 }
@@ -8,17 +8,16 @@ import (
 
 type LocalDistributor struct {
 	Directory string
-	FileName  string
 }
 
-func (d LocalDistributor) Distribute(content *bytes.Buffer) error {
+func (d LocalDistributor) Distribute(name string, content *bytes.Buffer) error {
 	if _, err := os.Stat(d.Directory); os.IsNotExist(err) {
 		if err := os.Mkdir(d.Directory, os.ModePerm); err != nil {
 			return err
 		}
 	}
 
-	filePath := path.Join(d.Directory, d.FileName)
+	filePath := path.Join(d.Directory, name)
 
 	file, err := os.Create(filePath)
 	if err != nil {
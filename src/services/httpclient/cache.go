@@ -0,0 +1,190 @@
+package httpclient
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"menucko/services/resultcache"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const cachingHTTPClientLogPrefix = "[HTTP Cache]"
+
+var ErrNotCached = errors.New("response is not cached")
+
+// CachingHTTPClient stores responses in a directory. Fresh responses are served without
+// a request, stale ones are revalidated with "If-None-Match" and "If-Modified-Since".
+// In offline mode only cached responses are served, regardless of their age.
+type CachingHTTPClient struct {
+	Fetcher Fetcher
+	Dir     string
+	TTL     time.Duration
+	Offline bool
+}
+
+type cacheEntry struct {
+	URL        string      `json:"url"`
+	FinalURL   string      `json:"finalUrl"`
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header"`
+	StoredAt   time.Time   `json:"storedAt"`
+}
+
+func (httpClient CachingHTTPClient) DownloadHTML(ctx context.Context, url string) (string, error) {
+	res, err := httpClient.Download(ctx, url)
+	if err != nil {
+		return "", err
+	}
+
+	return DecodeHTML(res)
+}
+
+func (httpClient CachingHTTPClient) Download(ctx context.Context, url string) (*Response, error) {
+	entry, body, err := httpClient.load(url)
+	if err != nil && !errors.Is(err, ErrNotCached) {
+		httpClient.log("Ignoring unreadable cache entry for URL \"%s\", Err: %v", url, err)
+	}
+
+	cached := err == nil
+
+	if httpClient.Offline {
+		if !cached {
+			return nil, fmt.Errorf("%w: \"%s\" (offline mode)", ErrNotCached, url)
+		}
+
+		httpClient.log("Serving URL \"%s\" from cache (offline mode)", url)
+
+		return entry.response(body), nil
+	}
+
+	if cached && time.Since(entry.StoredAt) < httpClient.TTL {
+		httpClient.log("Serving fresh URL \"%s\" from cache", url)
+
+		return entry.response(body), nil
+	}
+
+	header := http.Header{}
+
+	if cached {
+		if etag := entry.Header.Get("ETag"); len(etag) != 0 {
+			header.Set("If-None-Match", etag)
+		}
+
+		if lastModified := entry.Header.Get("Last-Modified"); len(lastModified) != 0 {
+			header.Set("If-Modified-Since", lastModified)
+		}
+	}
+
+	res, err := httpClient.Fetcher.Fetch(ctx, url, header)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode == http.StatusNotModified {
+		if !cached {
+			return nil, fmt.Errorf("\"%s\" responded with 304 to an unconditional request", url)
+		}
+
+		httpClient.log("URL \"%s\" not modified, serving from cache", url)
+
+		entry.StoredAt = time.Now()
+
+		if err := httpClient.storeEntry(entry); err != nil {
+			httpClient.log("Failed to refresh cache entry for URL \"%s\", Err: %v", url, err)
+		}
+
+		return entry.response(body), nil
+	}
+
+	if err := httpClient.store(url, res); err != nil {
+		httpClient.log("Failed to cache URL \"%s\", Err: %v", url, err)
+	}
+
+	return res, nil
+}
+
+func (entry cacheEntry) response(body []byte) *Response {
+	return &Response{
+		StatusCode: entry.StatusCode,
+		Body:       body,
+		URL:        entry.FinalURL,
+		Header:     entry.Header,
+	}
+}
+
+func (httpClient CachingHTTPClient) load(url string) (cacheEntry, []byte, error) {
+	var entry cacheEntry
+
+	metaPath, bodyPath := httpClient.paths(url)
+
+	meta, err := os.ReadFile(metaPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return entry, nil, ErrNotCached
+	}
+
+	if err != nil {
+		return entry, nil, err
+	}
+
+	if err = json.Unmarshal(meta, &entry); err != nil {
+		return entry, nil, err
+	}
+
+	body, err := os.ReadFile(bodyPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return entry, nil, ErrNotCached
+	}
+
+	return entry, body, err
+}
+
+func (httpClient CachingHTTPClient) store(url string, res *Response) error {
+	if err := os.MkdirAll(httpClient.Dir, os.ModePerm); err != nil {
+		return err
+	}
+
+	_, bodyPath := httpClient.paths(url)
+
+	if err := resultcache.WriteFileAtomic(bodyPath, res.Body); err != nil {
+		return err
+	}
+
+	return httpClient.storeEntry(cacheEntry{
+		URL:        url,
+		FinalURL:   res.URL,
+		StatusCode: res.StatusCode,
+		Header:     res.Header,
+		StoredAt:   time.Now(),
+	})
+}
+
+func (httpClient CachingHTTPClient) storeEntry(entry cacheEntry) error {
+	meta, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	metaPath, _ := httpClient.paths(entry.URL)
+
+	return resultcache.WriteFileAtomic(metaPath, meta)
+}
+
+func (httpClient CachingHTTPClient) paths(url string) (string, string) {
+	hash := sha256.Sum256([]byte(url))
+	name := hex.EncodeToString(hash[:])
+
+	return filepath.Join(httpClient.Dir, name+".json"), filepath.Join(httpClient.Dir, name+".body")
+}
+
+func (CachingHTTPClient) log(format string, v ...any) {
+	message := cachingHTTPClientLogPrefix + " " + fmt.Sprintf(format, v...)
+
+	log.Println(message)
+}
@@ -0,0 +1,72 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCachingHTTPClient(t *testing.T) {
+	var requests, conditionalRequests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			conditionalRequests.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte("menu"))
+	}))
+	defer server.Close()
+
+	httpClient := CachingHTTPClient{
+		Fetcher: NewProdHTTPClient(testConfig),
+		Dir:     t.TempDir(),
+		TTL:     time.Hour,
+	}
+
+	download := func() {
+		t.Helper()
+
+		content, err := httpClient.DownloadHTML(context.Background(), server.URL)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if content != "menu" {
+			t.Fatalf("unexpected content \"%s\"", content)
+		}
+	}
+
+	download()
+	download()
+
+	if requests.Load() != 1 {
+		t.Errorf("expected a fresh entry to be served without a request, got %d requests", requests.Load())
+	}
+
+	httpClient.TTL = 0
+
+	download()
+
+	if requests.Load() != 2 || conditionalRequests.Load() != 1 {
+		t.Errorf("expected a stale entry to be revalidated, got %d requests and %d conditional", requests.Load(), conditionalRequests.Load())
+	}
+
+	httpClient.Offline = true
+	server.Close()
+
+	download()
+
+	if _, err := httpClient.Download(context.Background(), server.URL+"/other"); !errors.Is(err, ErrNotCached) {
+		t.Errorf("expected ErrNotCached in offline mode, got %v", err)
+	}
+}
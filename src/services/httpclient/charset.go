@@ -0,0 +1,19 @@
+package httpclient
+
+import (
+	"golang.org/x/net/html/charset"
+)
+
+// DecodeHTML transcodes the response body to UTF-8. The encoding is taken from a BOM,
+// the "Content-Type" header or a "<meta charset>" tag, in that order, falling back
+// to windows-1252 like browsers do.
+func DecodeHTML(res *Response) (string, error) {
+	encoding, _, _ := charset.DetermineEncoding(res.Body, res.Header.Get("Content-Type"))
+
+	content, err := encoding.NewDecoder().Bytes(res.Body)
+	if err != nil {
+		return "", err
+	}
+
+	return string(content), nil
+}
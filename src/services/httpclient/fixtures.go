@@ -0,0 +1,207 @@
+package httpclient
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"menucko/services/resultcache"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+const fixtureIndexFile = "index.json"
+
+var ErrNoFixture = errors.New("no fixture recorded for URL")
+
+// fixture is an entry of the "index.json" file in a fixture directory, the body is
+// stored next to it in File.
+type fixture struct {
+	URL        string      `json:"url"`
+	File       string      `json:"file"`
+	FinalURL   string      `json:"finalUrl"`
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header"`
+}
+
+// RecordingHTTPClient saves every response of the wrapped client into a fixture
+// directory, which can be served back by ReplayHTTPClient.
+type RecordingHTTPClient struct {
+	httpClient HTTPClient
+	dir        string
+
+	mutex    sync.Mutex
+	fixtures map[string]fixture
+}
+
+func NewRecordingHTTPClient(httpClient HTTPClient, dir string) (*RecordingHTTPClient, error) {
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	fixtures, err := loadFixtures(dir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	if fixtures == nil {
+		fixtures = make(map[string]fixture)
+	}
+
+	return &RecordingHTTPClient{
+		httpClient: httpClient,
+		dir:        dir,
+		fixtures:   fixtures,
+	}, nil
+}
+
+func (httpClient *RecordingHTTPClient) DownloadHTML(ctx context.Context, url string) (string, error) {
+	res, err := httpClient.Download(ctx, url)
+	if err != nil {
+		return "", err
+	}
+
+	return DecodeHTML(res)
+}
+
+func (httpClient *RecordingHTTPClient) Download(ctx context.Context, url string) (*Response, error) {
+	res, err := httpClient.httpClient.Download(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := httpClient.record(url, res); err != nil {
+		return nil, fmt.Errorf("recording \"%s\" failed: %w", url, err)
+	}
+
+	return res, nil
+}
+
+func (httpClient *RecordingHTTPClient) record(url string, res *Response) error {
+	header := res.Header.Clone()
+	header.Del("Set-Cookie")
+
+	entry := fixture{
+		URL:        url,
+		File:       fixtureFileName(url, header.Get("Content-Type")),
+		FinalURL:   res.URL,
+		StatusCode: res.StatusCode,
+		Header:     header,
+	}
+
+	if err := resultcache.WriteFileAtomic(filepath.Join(httpClient.dir, entry.File), res.Body); err != nil {
+		return err
+	}
+
+	httpClient.mutex.Lock()
+	defer httpClient.mutex.Unlock()
+
+	httpClient.fixtures[url] = entry
+
+	entries := make([]fixture, 0, len(httpClient.fixtures))
+	for _, entry := range httpClient.fixtures {
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].URL < entries[j].URL
+	})
+
+	index, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return resultcache.WriteFileAtomic(filepath.Join(httpClient.dir, fixtureIndexFile), index)
+}
+
+// ReplayHTTPClient serves responses recorded by RecordingHTTPClient by their URL.
+type ReplayHTTPClient struct {
+	dir      string
+	fixtures map[string]fixture
+}
+
+func NewReplayHTTPClient(dir string) (*ReplayHTTPClient, error) {
+	fixtures, err := loadFixtures(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReplayHTTPClient{
+		dir:      dir,
+		fixtures: fixtures,
+	}, nil
+}
+
+func (httpClient *ReplayHTTPClient) DownloadHTML(ctx context.Context, url string) (string, error) {
+	res, err := httpClient.Download(ctx, url)
+	if err != nil {
+		return "", err
+	}
+
+	return DecodeHTML(res)
+}
+
+func (httpClient *ReplayHTTPClient) Download(ctx context.Context, url string) (*Response, error) {
+	entry, ok := httpClient.fixtures[url]
+	if !ok {
+		return nil, fmt.Errorf("%w \"%s\" in \"%s\"", ErrNoFixture, url, httpClient.dir)
+	}
+
+	body, err := os.ReadFile(filepath.Join(httpClient.dir, entry.File))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Response{
+		StatusCode: entry.StatusCode,
+		Body:       body,
+		URL:        entry.FinalURL,
+		Header:     entry.Header,
+	}, nil
+}
+
+func loadFixtures(dir string) (map[string]fixture, error) {
+	content, err := os.ReadFile(filepath.Join(dir, fixtureIndexFile))
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []fixture
+	if err = json.Unmarshal(content, &entries); err != nil {
+		return nil, fmt.Errorf("fixture index in \"%s\" is not valid JSON: %w", dir, err)
+	}
+
+	fixtures := make(map[string]fixture, len(entries))
+	for _, entry := range entries {
+		fixtures[entry.URL] = entry
+	}
+
+	return fixtures, nil
+}
+
+func fixtureFileName(url string, contentType string) string {
+	hash := sha256.Sum256([]byte(url))
+	name := hex.EncodeToString(hash[:6])
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+
+	switch mediaType {
+	case "text/html":
+		return name + ".html"
+	case "image/jpeg":
+		return name + ".jpg"
+	case "image/png":
+		return name + ".png"
+	case "application/pdf":
+		return name + ".pdf"
+	}
+
+	return name + ".bin"
+}
@@ -0,0 +1,66 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=windows-1250")
+		_, _ = w.Write([]byte("<img src=\"menu.jpg\"> \xe8aj"))
+	})
+	mux.HandleFunc("/menu.jpg", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/jpeg")
+		_, _ = w.Write([]byte{0xFF, 0xD8, 0xFF})
+	})
+
+	server := httptest.NewServer(mux)
+	dir := t.TempDir()
+
+	recorder, err := NewRecordingHTTPClient(NewProdHTTPClient(testConfig), dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err = recorder.DownloadHTML(context.Background(), server.URL+"/"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err = recorder.Download(context.Background(), server.URL+"/menu.jpg"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	server.Close()
+
+	replayer, err := NewReplayHTTPClient(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	content, err := replayer.DownloadHTML(context.Background(), server.URL+"/")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if content != "<img src=\"menu.jpg\"> čaj" {
+		t.Errorf("unexpected replayed content \"%s\"", content)
+	}
+
+	res, err := replayer.Download(context.Background(), server.URL+"/menu.jpg")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(res.Body) != 3 || res.Header.Get("Content-Type") != "image/jpeg" {
+		t.Errorf("unexpected replayed response %+v", res)
+	}
+
+	if _, err = replayer.Download(context.Background(), server.URL+"/missing"); !errors.Is(err, ErrNoFixture) {
+		t.Errorf("expected ErrNoFixture, got %v", err)
+	}
+}
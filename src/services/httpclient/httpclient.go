@@ -1,45 +1,280 @@
 package httpclient
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"io"
+	"log"
+	"math/rand"
+	"net"
 	"net/http"
+	"syscall"
+	"time"
 )
 
 const UserAgent = "Mozilla/5.0"
 
+const httpClientLogPrefix = "[HTTP Client]"
+
+var ErrBodyTooLarge = errors.New("response body exceeds the maximum size")
+
 type HTTPClient interface {
-	DownloadHTML(url string) (string, error)
+	// DownloadHTML returns the page transcoded to UTF-8.
+	DownloadHTML(ctx context.Context, url string) (string, error)
+	// Download returns the raw response body, e.g. of an image or a PDF.
+	Download(ctx context.Context, url string) (*Response, error)
+}
+
+// Fetcher performs a GET request with additional request headers. Unlike HTTPClient
+// it doesn't treat a "304 Not Modified" response as an error.
+type Fetcher interface {
+	Fetch(ctx context.Context, url string, header http.Header) (*Response, error)
+}
+
+type Response struct {
+	StatusCode int
+	Body       []byte
+	// URL is the final URL after following redirects.
+	URL    string
+	Header http.Header
 }
 
 type DevHTTPClient struct {
 	HTMLContent string
 }
 
-func (httpClient DevHTTPClient) DownloadHTML(url string) (string, error) {
+func (httpClient DevHTTPClient) DownloadHTML(ctx context.Context, url string) (string, error) {
 	return httpClient.HTMLContent, nil
 }
 
-type ProdHTTPClient struct{}
+func (httpClient DevHTTPClient) Download(ctx context.Context, url string) (*Response, error) {
+	return &Response{
+		StatusCode: http.StatusOK,
+		Body:       []byte(httpClient.HTMLContent),
+		URL:        url,
+		Header:     http.Header{},
+	}, nil
+}
+
+type StatusError struct {
+	URL        string
+	StatusCode int
+}
+
+func (err *StatusError) Error() string {
+	return fmt.Sprintf("request to \"%s\" failed with status %d %s", err.URL, err.StatusCode, http.StatusText(err.StatusCode))
+}
+
+// Temporary reports whether the request may succeed when retried.
+func (err *StatusError) Temporary() bool {
+	switch err.StatusCode {
+	case http.StatusRequestTimeout,
+		http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+
+	return false
+}
+
+type Config struct {
+	// Timeout of a single attempt, including reading the body.
+	Timeout time.Duration
+	// MaxBodySize is the maximum number of bytes read from a response body.
+	MaxBodySize int64
+	// MaxRetries is the number of attempts made after the first one failed with a transient error.
+	MaxRetries int
+	// Backoff is the delay before the first retry, it doubles with every next retry up to MaxBackoff.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+var DefaultConfig = Config{
+	Timeout:     20 * time.Second,
+	MaxBodySize: 20 << 20,
+	MaxRetries:  3,
+	Backoff:     500 * time.Millisecond,
+	MaxBackoff:  5 * time.Second,
+}
+
+type ProdHTTPClient struct {
+	config Config
+	client *http.Client
+}
 
-func (ProdHTTPClient) DownloadHTML(url string) (string, error) {
-	req, err := http.NewRequest("GET", url, nil)
+func NewProdHTTPClient(config Config) *ProdHTTPClient {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = 4
+
+	return &ProdHTTPClient{
+		config: config,
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   config.Timeout,
+		},
+	}
+}
+
+func (httpClient *ProdHTTPClient) DownloadHTML(ctx context.Context, url string) (string, error) {
+	res, err := httpClient.Download(ctx, url)
 	if err != nil {
 		return "", err
 	}
 
-	req.Header.Set("User-Agent", UserAgent)
+	return DecodeHTML(res)
+}
+
+func (httpClient *ProdHTTPClient) Download(ctx context.Context, url string) (*Response, error) {
+	return httpClient.Fetch(ctx, url, nil)
+}
+
+func (httpClient *ProdHTTPClient) Fetch(ctx context.Context, url string, header http.Header) (*Response, error) {
+	var err error
+
+	for attempt := 0; ; attempt++ {
+		var res *Response
 
-	client := http.Client{}
+		res, err = httpClient.get(ctx, url, header)
+		if err == nil {
+			return res, nil
+		}
+
+		if ctx.Err() != nil {
+			return nil, contextError(ctx, err)
+		}
+
+		if attempt >= httpClient.config.MaxRetries || !isTransient(ctx, err) {
+			return nil, err
+		}
+
+		delay := httpClient.backoff(attempt)
+
+		httpClient.log("Attempt %d for URL \"%s\" failed, retrying in %v, Err: %v", attempt+1, url, delay, err)
+
+		timer := time.NewTimer(delay)
+
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, contextError(ctx, err)
+		}
+	}
+}
+
+// contextError reports that the context is done rather than the error of the last
+// attempt, so callers see a timeout, but keeps the last error for the logs.
+func contextError(ctx context.Context, err error) error {
+	if errors.Is(err, ctx.Err()) {
+		return err
+	}
 
-	res, err := client.Do(req)
+	return fmt.Errorf("%w, last attempt failed: %w", ctx.Err(), err)
+}
+
+func (httpClient *ProdHTTPClient) get(ctx context.Context, url string, header http.Header) (*Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+
+	for key, values := range header {
+		req.Header[key] = values
 	}
 
-	content, err := io.ReadAll(res.Body)
+	req.Header.Set("User-Agent", UserAgent)
+
+	res, err := httpClient.client.Do(req)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotModified {
+		return &Response{
+			StatusCode: res.StatusCode,
+			URL:        res.Request.URL.String(),
+			Header:     res.Header,
+		}, nil
+	}
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		// Drain a bit of the body so the connection can be reused.
+		_, _ = io.CopyN(io.Discard, res.Body, 4<<10)
+
+		return nil, &StatusError{URL: url, StatusCode: res.StatusCode}
+	}
+
+	body := io.Reader(res.Body)
+	if httpClient.config.MaxBodySize > 0 {
+		body = io.LimitReader(res.Body, httpClient.config.MaxBodySize+1)
+	}
+
+	content, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+
+	if httpClient.config.MaxBodySize > 0 && int64(len(content)) > httpClient.config.MaxBodySize {
+		return nil, fmt.Errorf("%w: \"%s\" is larger than %d bytes", ErrBodyTooLarge, url, httpClient.config.MaxBodySize)
+	}
+
+	return &Response{
+		StatusCode: res.StatusCode,
+		Body:       content,
+		URL:        res.Request.URL.String(),
+		Header:     res.Header,
+	}, nil
+}
+
+// backoff returns an exponentially growing delay with jitter, so that retries
+// to the same host don't happen in lockstep.
+func (httpClient *ProdHTTPClient) backoff(attempt int) time.Duration {
+	delay := httpClient.config.Backoff << attempt
+
+	if httpClient.config.MaxBackoff > 0 && (delay > httpClient.config.MaxBackoff || delay <= 0) {
+		delay = httpClient.config.MaxBackoff
+	}
+
+	if delay <= 0 {
+		return 0
+	}
+
+	half := delay / 2
+
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+func isTransient(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.Temporary()
 	}
 
-	return string(content), nil
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	// A connection closed before the whole response arrived shows up as an EOF.
+	// Other errors, e.g. a bad URL or a failed TLS handshake, won't go away by retrying.
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+func (*ProdHTTPClient) log(format string, v ...any) {
+	message := httpClientLogPrefix + " " + fmt.Sprintf(format, v...)
+
+	log.Println(message)
 }
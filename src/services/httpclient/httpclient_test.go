@@ -0,0 +1,348 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+var testConfig = Config{
+	Timeout:     time.Second,
+	MaxBodySize: 1 << 10,
+	MaxRetries:  2,
+	Backoff:     time.Millisecond,
+	MaxBackoff:  5 * time.Millisecond,
+}
+
+func TestDownloadHTML(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("User-Agent") != UserAgent {
+			t.Errorf("expected User-Agent \"%s\", got \"%s\"", UserAgent, r.Header.Get("User-Agent"))
+		}
+
+		_, _ = w.Write([]byte("<p>Menu</p>"))
+	}))
+	defer server.Close()
+
+	content, err := NewProdHTTPClient(testConfig).DownloadHTML(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if content != "<p>Menu</p>" {
+		t.Errorf("unexpected content \"%s\"", content)
+	}
+}
+
+func TestDownloadHTMLStatusError(t *testing.T) {
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	_, err := NewProdHTTPClient(testConfig).DownloadHTML(context.Background(), server.URL)
+
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) {
+		t.Fatalf("expected StatusError, got %v", err)
+	}
+
+	if statusErr.StatusCode != http.StatusNotFound {
+		t.Errorf("expected status %d, got %d", http.StatusNotFound, statusErr.StatusCode)
+	}
+
+	if requests.Load() != 1 {
+		t.Errorf("expected 1 request for a permanent error, got %d", requests.Load())
+	}
+}
+
+func TestDownloadHTMLRetriesTransientErrors(t *testing.T) {
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	content, err := NewProdHTTPClient(testConfig).DownloadHTML(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if content != "ok" || requests.Load() != 3 {
+		t.Errorf("expected \"ok\" after 3 requests, got \"%s\" after %d", content, requests.Load())
+	}
+}
+
+func TestDownloadHTMLGivesUpAfterMaxRetries(t *testing.T) {
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	_, err := NewProdHTTPClient(testConfig).DownloadHTML(context.Background(), server.URL)
+
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusBadGateway {
+		t.Fatalf("expected 502 StatusError, got %v", err)
+	}
+
+	if int(requests.Load()) != testConfig.MaxRetries+1 {
+		t.Errorf("expected %d requests, got %d", testConfig.MaxRetries+1, requests.Load())
+	}
+}
+
+func TestDownloadHTMLRetriesClosedConnection(t *testing.T) {
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			_ = conn.Close()
+			return
+		}
+
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	content, err := NewProdHTTPClient(testConfig).DownloadHTML(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if content != "ok" || requests.Load() != 2 {
+		t.Errorf("expected \"ok\" after 2 requests, got \"%s\" after %d", content, requests.Load())
+	}
+}
+
+func TestDownloadHTMLDoesNotRetryTLSErrors(t *testing.T) {
+	var requests atomic.Int32
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			requests.Add(1)
+		}
+	}
+
+	server.StartTLS()
+	defer server.Close()
+
+	config := testConfig
+	config.Backoff = time.Second
+	config.MaxBackoff = time.Second
+
+	start := time.Now()
+
+	// The client doesn't trust the test server's certificate.
+	_, err := NewProdHTTPClient(config).DownloadHTML(context.Background(), server.URL)
+	if err == nil {
+		t.Fatal("expected certificate error")
+	}
+
+	if requests.Load() != 1 || time.Since(start) > 500*time.Millisecond {
+		t.Errorf("expected 1 connection without backoff, got %d in %v", requests.Load(), time.Since(start))
+	}
+}
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{&StatusError{StatusCode: http.StatusServiceUnavailable}, true},
+		{&StatusError{StatusCode: http.StatusTooManyRequests}, true},
+		{&StatusError{StatusCode: http.StatusNotImplemented}, false},
+		{&StatusError{StatusCode: http.StatusNotFound}, false},
+		{&url.Error{Op: "Get", Err: syscall.ECONNREFUSED}, true},
+		{&url.Error{Op: "Get", Err: syscall.ECONNRESET}, true},
+		{&url.Error{Op: "Get", Err: io.ErrUnexpectedEOF}, true},
+		{&url.Error{Op: "Get", Err: errors.New("unsupported protocol scheme \"ftp\"")}, false},
+		{ErrBodyTooLarge, false},
+	}
+
+	for _, test := range tests {
+		if got := isTransient(context.Background(), test.err); got != test.want {
+			t.Errorf("isTransient(%v) = %v, want %v", test.err, got, test.want)
+		}
+	}
+}
+
+func TestDownloadHTMLBodyTooLarge(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(strings.Repeat("a", int(testConfig.MaxBodySize)+1)))
+	}))
+	defer server.Close()
+
+	_, err := NewProdHTTPClient(testConfig).DownloadHTML(context.Background(), server.URL)
+	if !errors.Is(err, ErrBodyTooLarge) {
+		t.Fatalf("expected ErrBodyTooLarge, got %v", err)
+	}
+}
+
+func TestDownloadHTMLTimeout(t *testing.T) {
+	release := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	config := testConfig
+	config.Timeout = 50 * time.Millisecond
+	config.MaxRetries = 0
+
+	start := time.Now()
+
+	_, err := NewProdHTTPClient(config).DownloadHTML(context.Background(), server.URL)
+	if err == nil {
+		t.Fatal("expected timeout error")
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("request took %v, expected it to time out", elapsed)
+	}
+}
+
+func TestDownloadHTMLContextCanceled(t *testing.T) {
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	config := testConfig
+	config.Backoff = time.Minute
+	config.MaxBackoff = time.Minute
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := NewProdHTTPClient(config).DownloadHTML(ctx, server.URL)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected the 503 StatusError of the last attempt, got %v", err)
+	}
+
+	if requests.Load() != 1 {
+		t.Errorf("expected no retries after the context is done, got %d requests", requests.Load())
+	}
+}
+
+func TestDownloadReturnsFinalURL(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/old", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/menu.jpg", http.StatusFound)
+	})
+	mux.HandleFunc("/menu.jpg", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/jpeg")
+		_, _ = w.Write([]byte{0xFF, 0xD8, 0xFF, 0x00})
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	res, err := NewProdHTTPClient(testConfig).Download(context.Background(), server.URL+"/old")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if res.URL != server.URL+"/menu.jpg" {
+		t.Errorf("expected final URL \"%s\", got \"%s\"", server.URL+"/menu.jpg", res.URL)
+	}
+
+	if res.Header.Get("Content-Type") != "image/jpeg" || len(res.Body) != 4 || res.Body[3] != 0x00 {
+		t.Errorf("unexpected response %+v", res)
+	}
+}
+
+func TestDownloadHTMLDecodesCharset(t *testing.T) {
+	// "Kurací vývar, čočka, ľadový čaj" encoded as windows-1250
+	win1250 := []byte("Kurac\xed v\xfdvar, \xe8o\xe8ka, \xbeadov\xfd \xe8aj")
+	expected := "Kurací vývar, čočka, ľadový čaj"
+
+	tests := map[string]struct {
+		contentType string
+		body        []byte
+		expected    string
+	}{
+		"content type header": {
+			contentType: "text/html; charset=windows-1250",
+			body:        append([]byte("<p>"), win1250...),
+			expected:    expected,
+		},
+		"meta charset": {
+			contentType: "text/html",
+			body:        append([]byte(`<meta charset="windows-1250"><p>`), win1250...),
+			expected:    expected,
+		},
+		"meta http-equiv": {
+			contentType: "text/html",
+			body:        []byte(`<meta http-equiv="Content-Type" content="text/html; charset=iso-8859-2"><p>` + "\xb5adov\xfd \xe8aj"),
+			expected:    "ľadový čaj",
+		},
+		"utf-8": {
+			contentType: "text/html; charset=utf-8",
+			body:        []byte("<p>" + expected),
+			expected:    expected,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", test.contentType)
+				_, _ = w.Write(test.body)
+			}))
+			defer server.Close()
+
+			content, err := NewProdHTTPClient(testConfig).DownloadHTML(context.Background(), server.URL)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !strings.HasSuffix(content, "<p>"+test.expected) {
+				t.Errorf("unexpected content \"%s\"", content)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package imageocr
+
+import (
+	"strings"
+)
+
+// EditDistance is the Levenshtein distance between two sequences.
+func EditDistance[T comparable](reference, hypothesis []T) int {
+	previous := make([]int, len(hypothesis)+1)
+	current := make([]int, len(hypothesis)+1)
+
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(reference); i++ {
+		current[0] = i
+
+		for j := 1; j <= len(hypothesis); j++ {
+			cost := 1
+			if reference[i-1] == hypothesis[j-1] {
+				cost = 0
+			}
+
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+
+		previous, current = current, previous
+	}
+
+	return previous[len(hypothesis)]
+}
+
+// normalizeText drops empty lines and collapses whitespace, so layout differences
+// which don't matter to the parsers aren't counted as errors.
+func normalizeText(text string) string {
+	var lines []string
+
+	for _, line := range strings.Split(text, "\n") {
+		if fields := strings.Fields(line); len(fields) > 0 {
+			lines = append(lines, strings.Join(fields, " "))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// TextErrors compares the recognised text with a hand-made transcript. It returns
+// the character and word edit counts together with the transcript lengths, so
+// rates can be summed over several images.
+func TextErrors(transcript, text string) (charEdits, chars, wordEdits, words int) {
+	transcript = normalizeText(transcript)
+	text = normalizeText(text)
+
+	transcriptWords := strings.Fields(transcript)
+
+	return EditDistance([]rune(transcript), []rune(text)), len([]rune(transcript)),
+		EditDistance(transcriptWords, strings.Fields(text)), len(transcriptWords)
+}
@@ -0,0 +1,13 @@
+package imageocr
+
+import (
+	"testing"
+)
+
+func TestTextErrors(t *testing.T) {
+	charEdits, chars, wordEdits, words := TextErrors("Menu 1 6,90 €\n\nKuracie  prsia\n", "Menu l 6,90 €\nKuracie prsla")
+
+	if charEdits != 2 || chars != 27 || wordEdits != 2 || words != 6 {
+		t.Errorf("unexpected errors %d/%d chars, %d/%d words", charEdits, chars, wordEdits, words)
+	}
+}
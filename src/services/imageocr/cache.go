@@ -0,0 +1,56 @@
+package imageocr
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"menucko/services/resultcache"
+)
+
+const cachingImageOcrLogPrefix = "[OCR Cache]"
+
+// ocrCacheVersion is part of every cache key. Bump it when the OCR output for the
+// same image and pipeline changes, e.g. after changing the line reconstruction.
+const ocrCacheVersion = "1"
+
+// CachingImageOcr serves OCR results of images it has already read from the cache,
+// so an unchanged menu image doesn't go through Tesseract again.
+type CachingImageOcr struct {
+	ImageOcr ImageOcr
+	Cache    resultcache.Cache
+	pipeline Pipeline
+}
+
+func (imageOcr CachingImageOcr) WithPipeline(pipeline Pipeline) ImageOcr {
+	imageOcr.ImageOcr = imageOcr.ImageOcr.WithPipeline(pipeline)
+	imageOcr.pipeline = pipeline
+
+	return imageOcr
+}
+
+func (imageOcr CachingImageOcr) ParseImageText(ctx context.Context, imgBytes []byte, contentType string) (string, error) {
+	return resultcache.Cached(imageOcr.Cache, imageOcr.key(imgBytes, "text"), imageOcr.log, func() (string, error) {
+		return imageOcr.ImageOcr.ParseImageText(ctx, imgBytes, contentType)
+	})
+}
+
+func (imageOcr CachingImageOcr) ParseImageLines(ctx context.Context, imgBytes []byte, contentType string) ([]Line, error) {
+	return resultcache.Cached(imageOcr.Cache, imageOcr.key(imgBytes, "lines"), imageOcr.log, func() ([]Line, error) {
+		return imageOcr.ImageOcr.ParseImageLines(ctx, imgBytes, contentType)
+	})
+}
+
+func (imageOcr CachingImageOcr) key(imgBytes []byte, kind string) string {
+	pipeline := imageOcr.pipeline
+	if pipeline == nil {
+		pipeline = DefaultPipeline
+	}
+
+	return resultcache.Key(imgBytes, "imageocr", ocrCacheVersion, kind, pipeline.String())
+}
+
+func (CachingImageOcr) log(format string, v ...any) {
+	message := cachingImageOcrLogPrefix + " " + fmt.Sprintf(format, v...)
+
+	log.Println(message)
+}
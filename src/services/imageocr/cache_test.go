@@ -0,0 +1,60 @@
+package imageocr
+
+import (
+	"context"
+	"menucko/services/resultcache"
+	"testing"
+)
+
+type countingImageOcr struct {
+	DevImageOcr
+	calls *int
+}
+
+func (imageOcr countingImageOcr) ParseImageLines(ctx context.Context, imgBytes []byte, contentType string) ([]Line, error) {
+	*imageOcr.calls++
+
+	return imageOcr.DevImageOcr.ParseImageLines(ctx, imgBytes, contentType)
+}
+
+func (imageOcr countingImageOcr) WithPipeline(pipeline Pipeline) ImageOcr {
+	return imageOcr
+}
+
+func TestCachingImageOcr(t *testing.T) {
+	calls := 0
+
+	var imageOcr ImageOcr = CachingImageOcr{
+		ImageOcr: countingImageOcr{DevImageOcr: DevImageOcr{ImgText: "Menu 1 6,90 €"}, calls: &calls},
+		Cache:    resultcache.Cache{Dir: t.TempDir()},
+	}
+
+	parse := func(imageOcr ImageOcr, imgBytes string) []Line {
+		t.Helper()
+
+		lines, err := imageOcr.ParseImageLines(context.Background(), []byte(imgBytes), "image/png")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		return lines
+	}
+
+	first := parse(imageOcr, "image")
+	second := parse(imageOcr, "image")
+
+	if calls != 1 {
+		t.Errorf("expected an unchanged image to be read once, got %d calls", calls)
+	}
+
+	if LinesText(second) != LinesText(first) || second[0].Words[0].Box != first[0].Words[0].Box {
+		t.Errorf("cached lines %v differ from %v", second, first)
+	}
+
+	parse(imageOcr.WithPipeline(Pipeline{"grayscale"}), "image")
+	parse(imageOcr, "changed image")
+
+	if calls != 3 {
+		t.Errorf("expected a new pipeline and a changed image to be read again, got %d calls", calls)
+	}
+}
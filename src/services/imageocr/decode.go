@@ -0,0 +1,181 @@
+package imageocr
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"mime"
+	"strings"
+
+	"golang.org/x/image/webp"
+)
+
+type Format string
+
+const (
+	FormatJPEG Format = "jpeg"
+	FormatPNG  Format = "png"
+	FormatGIF  Format = "gif"
+	FormatWebP Format = "webp"
+)
+
+var ErrUnsupportedFormat = errors.New("unsupported image format")
+
+// ErrAnimatedWebP is returned for animated WebP images, the WebP decoder reads
+// still images only.
+var ErrAnimatedWebP = errors.New("unsupported animated WebP")
+
+var contentTypeFormats = map[string]Format{
+	"image/jpeg": FormatJPEG,
+	"image/jpg":  FormatJPEG,
+	"image/png":  FormatPNG,
+	"image/gif":  FormatGIF,
+	"image/webp": FormatWebP,
+}
+
+// DetectFormat recognises the image by its magic bytes and falls back to the
+// Content-Type header, which web servers often get wrong for uploaded files.
+func DetectFormat(imgBytes []byte, contentType string) (Format, error) {
+	switch {
+	case bytes.HasPrefix(imgBytes, []byte{0xFF, 0xD8, 0xFF}):
+		return FormatJPEG, nil
+	case bytes.HasPrefix(imgBytes, []byte("\x89PNG\r\n\x1a\n")):
+		return FormatPNG, nil
+	case bytes.HasPrefix(imgBytes, []byte("GIF87a")), bytes.HasPrefix(imgBytes, []byte("GIF89a")):
+		return FormatGIF, nil
+	case len(imgBytes) >= 12 && string(imgBytes[:4]) == "RIFF" && string(imgBytes[8:12]) == "WEBP":
+		return FormatWebP, nil
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err == nil {
+		if format, ok := contentTypeFormats[strings.ToLower(mediaType)]; ok {
+			return format, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: content type \"%s\"", ErrUnsupportedFormat, contentType)
+}
+
+// DecodeImages decodes every frame of the image. Animated GIFs are often used as
+// slideshows with one part of the menu per slide, so each distinct frame is
+// returned fully composited.
+func DecodeImages(imgBytes []byte, contentType string) ([]image.Image, error) {
+	format, err := DetectFormat(imgBytes, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	var img image.Image
+
+	switch format {
+	case FormatJPEG:
+		img, err = jpeg.Decode(bytes.NewReader(imgBytes))
+	case FormatPNG:
+		img, err = png.Decode(bytes.NewReader(imgBytes))
+	case FormatWebP:
+		if isAnimatedWebP(imgBytes) {
+			return nil, ErrAnimatedWebP
+		}
+
+		img, err = webp.Decode(bytes.NewReader(imgBytes))
+	case FormatGIF:
+		return decodeGIFFrames(imgBytes)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return []image.Image{img}, nil
+}
+
+// isAnimatedWebP looks for the animation flag of the extended format header and
+// for animation chunks, as some encoders don't set the flag.
+func isAnimatedWebP(imgBytes []byte) bool {
+	const headerSize, chunkHeaderSize = 12, 8
+	const animationFlag = 0x02
+
+	for offset := headerSize; offset+chunkHeaderSize <= len(imgBytes); {
+		fourCC := string(imgBytes[offset : offset+4])
+		size := int(binary.LittleEndian.Uint32(imgBytes[offset+4 : offset+8]))
+
+		switch fourCC {
+		case "VP8X":
+			if offset+chunkHeaderSize < len(imgBytes) && imgBytes[offset+chunkHeaderSize]&animationFlag != 0 {
+				return true
+			}
+		case "ANIM", "ANMF":
+			return true
+		}
+
+		// Chunks are padded to an even size.
+		next := offset + chunkHeaderSize + size + size%2
+		if next <= offset {
+			break
+		}
+
+		offset = next
+	}
+
+	return false
+}
+
+func decodeGIFFrames(imgBytes []byte) ([]image.Image, error) {
+	animation, err := gif.DecodeAll(bytes.NewReader(imgBytes))
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := image.Rect(0, 0, animation.Config.Width, animation.Config.Height)
+	if bounds.Empty() && len(animation.Image) > 0 {
+		bounds = animation.Image[0].Bounds()
+	}
+
+	canvas := image.NewRGBA(bounds)
+	var frames []image.Image
+	var lastHash [sha256.Size]byte
+
+	for index, frame := range animation.Image {
+		var previous *image.RGBA
+
+		disposal := byte(gif.DisposalNone)
+		if index < len(animation.Disposal) {
+			disposal = animation.Disposal[index]
+		}
+
+		if disposal == gif.DisposalPrevious {
+			previous = image.NewRGBA(bounds)
+			copy(previous.Pix, canvas.Pix)
+		}
+
+		draw.Draw(canvas, frame.Bounds(), frame, frame.Bounds().Min, draw.Over)
+
+		// Frames are flattened on white, transparent areas would read as black.
+		flattened := image.NewRGBA(bounds)
+		draw.Draw(flattened, bounds, image.NewUniform(color.White), image.Point{}, draw.Src)
+		draw.Draw(flattened, bounds, canvas, bounds.Min, draw.Over)
+
+		if hash := sha256.Sum256(flattened.Pix); index == 0 || hash != lastHash {
+			frames = append(frames, flattened)
+			lastHash = hash
+		}
+
+		switch disposal {
+		case gif.DisposalBackground:
+			draw.Draw(canvas, frame.Bounds(), image.Transparent, image.Point{}, draw.Src)
+		case gif.DisposalPrevious:
+			canvas = previous
+		}
+	}
+
+	return frames, nil
+}
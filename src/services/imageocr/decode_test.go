@@ -0,0 +1,147 @@
+package imageocr
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"testing"
+)
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		name        string
+		imgBytes    []byte
+		contentType string
+		want        Format
+	}{
+		{"jpeg magic", []byte{0xFF, 0xD8, 0xFF, 0xE0}, "", FormatJPEG},
+		{"png magic", []byte("\x89PNG\r\n\x1a\n...."), "", FormatPNG},
+		{"gif magic", []byte("GIF89a...."), "", FormatGIF},
+		{"webp magic", []byte("RIFF\x00\x00\x00\x00WEBPVP8 "), "", FormatWebP},
+		{"magic wins over header", []byte("\x89PNG\r\n\x1a\n...."), "image/jpeg", FormatPNG},
+		{"header fallback", []byte("????"), "image/webp", FormatWebP},
+		{"header with parameters", []byte("????"), "Image/JPEG; charset=binary", FormatJPEG},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			got, err := DetectFormat(test.imgBytes, test.contentType)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got != test.want {
+				t.Errorf("got %s, want %s", got, test.want)
+			}
+		})
+	}
+
+	if _, err := DetectFormat([]byte("<html>"), "text/html"); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("expected ErrUnsupportedFormat, got %v", err)
+	}
+}
+
+func TestDecodeImagesPNG(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 8, 4))
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+
+	// The header is wrong on purpose, the magic bytes decide.
+	frames, err := DecodeImages(buf.Bytes(), "image/jpeg")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(frames) != 1 || frames[0].Bounds() != img.Bounds() {
+		t.Errorf("unexpected frames: %v", frames)
+	}
+}
+
+func TestDecodeImagesGIFFrames(t *testing.T) {
+	palette := color.Palette{color.Transparent, color.Black}
+	bounds := image.Rect(0, 0, 4, 2)
+
+	// The first slide fills the left half, the second adds the right half as a
+	// partial frame and the third repeats it unchanged.
+	left := image.NewPaletted(image.Rect(0, 0, 2, 2), palette)
+	right := image.NewPaletted(image.Rect(2, 0, 4, 2), palette)
+	empty := image.NewPaletted(bounds, palette)
+
+	for _, frame := range []*image.Paletted{left, right} {
+		for y := frame.Rect.Min.Y; y < frame.Rect.Max.Y; y++ {
+			for x := frame.Rect.Min.X; x < frame.Rect.Max.X; x++ {
+				frame.SetColorIndex(x, y, 1)
+			}
+		}
+	}
+
+	animation := &gif.GIF{
+		Image:    []*image.Paletted{left, right, empty},
+		Delay:    []int{100, 100, 100},
+		Disposal: []byte{gif.DisposalNone, gif.DisposalNone, gif.DisposalNone},
+		Config:   image.Config{ColorModel: palette, Width: bounds.Dx(), Height: bounds.Dy()},
+	}
+
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, animation); err != nil {
+		t.Fatal(err)
+	}
+
+	frames, err := DecodeImages(buf.Bytes(), "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(frames) != 2 {
+		t.Fatalf("got %d frames, want 2", len(frames))
+	}
+
+	isBlack := func(img image.Image, x, y int) bool {
+		r, g, b, _ := img.At(x, y).RGBA()
+		return r == 0 && g == 0 && b == 0
+	}
+
+	if !isBlack(frames[0], 0, 0) || isBlack(frames[0], 3, 0) {
+		t.Error("first frame should have only the left half drawn on white")
+	}
+
+	if !isBlack(frames[1], 0, 0) || !isBlack(frames[1], 3, 0) {
+		t.Error("second frame should be composited over the first")
+	}
+}
+
+func TestDecodeImagesInvalidWebP(t *testing.T) {
+	if _, err := DecodeImages([]byte("RIFF\x00\x00\x00\x00WEBPVP8 "), ""); err == nil {
+		t.Error("expected truncated WebP to fail")
+	}
+}
+
+func TestDecodeImagesAnimatedWebP(t *testing.T) {
+	vp8x := []byte("VP8X\x0a\x00\x00\x00\x02\x00\x00\x00\x03\x00\x00\x03\x00\x00")
+	anim := []byte("ANIM\x06\x00\x00\x00\xff\xff\xff\xff\x00\x00")
+	anmf := []byte("ANMF\x00\x00\x00\x00")
+
+	tests := []struct {
+		name   string
+		chunks [][]byte
+	}{
+		{"animation flag", [][]byte{vp8x, anim, anmf}},
+		{"frames without the flag", [][]byte{anmf}},
+	}
+
+	for _, test := range tests {
+		imgBytes := append([]byte("RIFF\x00\x00\x00\x00WEBP"), bytes.Join(test.chunks, nil)...)
+
+		if _, err := DecodeImages(imgBytes, "image/webp"); !errors.Is(err, ErrAnimatedWebP) {
+			t.Errorf("%s: expected ErrAnimatedWebP, got %v", test.name, err)
+		}
+	}
+}
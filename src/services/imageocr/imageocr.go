@@ -2,56 +2,231 @@ package imageocr
 
 import (
 	"bytes"
-	"image/jpeg"
+	"context"
+	"image"
 	"image/png"
+	"strings"
 
-	"github.com/disintegration/imaging"
 	"github.com/otiai10/gosseract/v2"
 )
 
+// ImageOcr reads text from JPEG, PNG, GIF and WebP images. The content type is
+// only a hint for DetectFormat. Every frame of a multi-frame image is read, the
+// frames are separated by an empty line in the text.
 type ImageOcr interface {
-	ParseJpegText(imgBytes []byte) (string, error)
+	ParseImageText(ctx context.Context, imgBytes []byte, contentType string) (string, error)
+	ParseImageLines(ctx context.Context, imgBytes []byte, contentType string) ([]Line, error)
+	// WithPipeline returns a copy which preprocesses images with the pipeline.
+	WithPipeline(pipeline Pipeline) ImageOcr
 }
 
 type DevImageOcr struct {
 	ImgText string
+	Lines   []Line
 }
 
-func (imageOcr DevImageOcr) ParseJpegText(imgBytes []byte) (string, error) {
+func (imageOcr DevImageOcr) ParseImageText(ctx context.Context, imgBytes []byte, contentType string) (string, error) {
+	if imageOcr.ImgText == "" && len(imageOcr.Lines) > 0 {
+		return LinesText(imageOcr.Lines), nil
+	}
+
 	return imageOcr.ImgText, nil
 }
 
-type ProdImageOcr struct{}
+// ParseImageLines returns the configured lines, or lays out ImgText on a grid with
+// full confidence, so parsers can be tested with plain text fixtures.
+func (imageOcr DevImageOcr) ParseImageLines(ctx context.Context, imgBytes []byte, contentType string) ([]Line, error) {
+	if len(imageOcr.Lines) > 0 {
+		return imageOcr.Lines, nil
+	}
+
+	const charWidth, lineHeight = 10, 20
+
+	var words []Word
+
+	for lineIndex, line := range strings.Split(imageOcr.ImgText, "\n") {
+		offset := 0
+
+		for _, field := range strings.SplitAfter(line, " ") {
+			text := strings.TrimSpace(field)
+
+			if text != "" {
+				x := offset * charWidth
+				y := lineIndex * lineHeight
+
+				words = append(words, Word{
+					Text:       text,
+					Box:        image.Rect(x, y, x+len([]rune(text))*charWidth, y+lineHeight*3/4),
+					Confidence: 100,
+				})
+			}
+
+			offset += len([]rune(field))
+		}
+	}
+
+	return ReadingOrder(words), nil
+}
+
+func (imageOcr DevImageOcr) WithPipeline(pipeline Pipeline) ImageOcr {
+	return imageOcr
+}
+
+type ProdImageOcr struct {
+	// Pipeline preprocesses the image before OCR, DefaultPipeline is used when nil.
+	Pipeline Pipeline
+}
+
+func (imageOcr ProdImageOcr) WithPipeline(pipeline Pipeline) ImageOcr {
+	imageOcr.Pipeline = pipeline
 
-func (ProdImageOcr) ParseJpegText(imgBytes []byte) (string, error) {
-	img, err := jpeg.Decode(bytes.NewReader(imgBytes))
+	return imageOcr
+}
+
+type ocrResult[T any] struct {
+	value T
+	err   error
+}
+
+// runOcr runs Tesseract in its own goroutine, because it can't be interrupted, and
+// abandons it when the context is done. The buffered channel lets the goroutine
+// finish on its own.
+func runOcr[T any](ctx context.Context, parse func() (T, error)) (T, error) {
+	var zero T
+
+	if err := ctx.Err(); err != nil {
+		return zero, err
+	}
+
+	resultChan := make(chan ocrResult[T], 1)
+
+	go func() {
+		value, err := parse()
+		resultChan <- ocrResult[T]{value: value, err: err}
+	}()
+
+	select {
+	case result := <-resultChan:
+		return result.value, result.err
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	}
+}
+
+func (imageOcr ProdImageOcr) ParseImageText(ctx context.Context, imgBytes []byte, contentType string) (string, error) {
+	return runOcr(ctx, func() (string, error) {
+		imgs, err := DecodeImages(imgBytes, contentType)
+		if err != nil {
+			return "", err
+		}
+
+		texts := make([]string, 0, len(imgs))
+
+		for _, img := range imgs {
+			text, err := imageOcr.parseText(img)
+			if err != nil {
+				return "", err
+			}
+
+			texts = append(texts, strings.TrimSpace(text))
+		}
+
+		return strings.Join(texts, "\n\n"), nil
+	})
+}
+
+func (imageOcr ProdImageOcr) ParseImageLines(ctx context.Context, imgBytes []byte, contentType string) ([]Line, error) {
+	return runOcr(ctx, func() ([]Line, error) {
+		imgs, err := DecodeImages(imgBytes, contentType)
+		if err != nil {
+			return nil, err
+		}
+
+		var lines []Line
+
+		for _, img := range imgs {
+			frameLines, err := imageOcr.parseLines(img)
+			if err != nil {
+				return nil, err
+			}
+
+			lines = append(lines, frameLines...)
+		}
+
+		return lines, nil
+	})
+}
+
+func (imageOcr ProdImageOcr) parseText(img image.Image) (string, error) {
+	client, err := imageOcr.newClient(img, gosseract.PSM_SINGLE_BLOCK)
 	if err != nil {
 		return "", err
 	}
 
-	img = imaging.Resize(img, img.Bounds().Max.X*4, img.Bounds().Max.Y*4, imaging.Lanczos)
-	img = imaging.AdjustContrast(img, 10)
-	img = imaging.Sharpen(img, 4)
+	defer client.Close()
+
+	return client.Text()
+}
+
+// parseLines lets Tesseract segment the page on its own and rebuilds the lines
+// from word bounding boxes, so text in separate columns isn't interleaved.
+func (imageOcr ProdImageOcr) parseLines(img image.Image) ([]Line, error) {
+	client, err := imageOcr.newClient(img, gosseract.PSM_AUTO)
+	if err != nil {
+		return nil, err
+	}
+
+	defer client.Close()
+
+	boxes, err := client.GetBoundingBoxes(gosseract.RIL_WORD)
+	if err != nil {
+		return nil, err
+	}
+
+	words := make([]Word, 0, len(boxes))
+
+	for _, box := range boxes {
+		if strings.TrimSpace(box.Word) == "" {
+			continue
+		}
+
+		words = append(words, Word{
+			Text:       strings.TrimSpace(box.Word),
+			Box:        box.Box,
+			Confidence: box.Confidence,
+		})
+	}
+
+	return ReadingOrder(words), nil
+}
+
+func (imageOcr ProdImageOcr) newClient(img image.Image, pageSegMode gosseract.PageSegMode) (*gosseract.Client, error) {
+	img, err := imageOcr.Pipeline.Apply(img)
+	if err != nil {
+		return nil, err
+	}
 
 	var imgBuf bytes.Buffer
 	if err = png.Encode(&imgBuf, img); err != nil {
-		return "", err
+		return nil, err
 	}
 
 	client := gosseract.NewClient()
-	defer client.Close()
 
 	if err = client.SetLanguage("slk"); err != nil {
-		return "", err
+		client.Close()
+		return nil, err
 	}
 
-	if err = client.SetPageSegMode(gosseract.PSM_SINGLE_BLOCK); err != nil {
-		return "", err
+	if err = client.SetPageSegMode(pageSegMode); err != nil {
+		client.Close()
+		return nil, err
 	}
 
 	if err = client.SetImageFromBytes(imgBuf.Bytes()); err != nil {
-		return "", err
+		client.Close()
+		return nil, err
 	}
 
-	return client.Text()
+	return client, nil
 }
@@ -0,0 +1,139 @@
+package imageocr
+
+import (
+	"image"
+	"menucko/services/layout"
+	"sort"
+	"strings"
+)
+
+// Word is a word recognised by Tesseract with its bounding box in pixels of the
+// preprocessed image and a confidence between 0 and 100.
+type Word struct {
+	Text       string
+	Box        image.Rectangle
+	Confidence float64
+}
+
+// Line is a row of words sharing a baseline, ordered from left to right.
+type Line struct {
+	Words []Word
+}
+
+func (line Line) Text() string {
+	texts := make([]string, len(line.Words))
+
+	for index, word := range line.Words {
+		texts[index] = word.Text
+	}
+
+	return strings.Join(texts, " ")
+}
+
+func (line Line) Box() image.Rectangle {
+	var box image.Rectangle
+
+	for _, word := range line.Words {
+		box = box.Union(word.Box)
+	}
+
+	return box
+}
+
+// Confidence is the mean confidence of the words weighted by their length, so
+// a misread dish name outweighs a well read allergen number.
+func (line Line) Confidence() float64 {
+	var sum, weight float64
+
+	for _, word := range line.Words {
+		length := float64(len([]rune(word.Text)))
+
+		sum += word.Confidence * length
+		weight += length
+	}
+
+	if weight == 0 {
+		return 0
+	}
+
+	return sum / weight
+}
+
+// Cells splits the line at wide horizontal gaps, see layout.Cells.
+func (line Line) Cells() []Line {
+	var cells []Line
+
+	for _, words := range layout.Cells(line.Words, wordSpan) {
+		cells = append(cells, Line{Words: words})
+	}
+
+	return cells
+}
+
+func wordSpan(word Word) layout.Span {
+	return layout.Span{Start: float64(word.Box.Min.X), End: float64(word.Box.Max.X), Size: float64(word.Box.Dy())}
+}
+
+func LinesText(lines []Line) string {
+	texts := make([]string, len(lines))
+
+	for index, line := range lines {
+		texts[index] = line.Text()
+	}
+
+	return strings.Join(texts, "\n")
+}
+
+// ReadingOrder groups words into lines by vertical overlap and reads a multi-column
+// image column by column, see layout.ReadingOrder.
+func ReadingOrder(words []Word) []Line {
+	lines := groupLines(words)
+	lineWords := make([][]Word, len(lines))
+
+	for index, line := range lines {
+		lineWords[index] = line.Words
+	}
+
+	var ordered []Line
+
+	for _, part := range layout.ReadingOrder(lineWords, wordSpan) {
+		ordered = append(ordered, Line{Words: part.Items})
+	}
+
+	return ordered
+}
+
+func groupLines(words []Word) []Line {
+	sorted := make([]Word, len(words))
+	copy(sorted, words)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Box.Min.Y < sorted[j].Box.Min.Y
+	})
+
+	var lines []Line
+
+	for _, word := range sorted {
+		center := (word.Box.Min.Y + word.Box.Max.Y) / 2
+
+		if len(lines) > 0 {
+			last := &lines[len(lines)-1]
+			box := last.Box()
+
+			if center >= box.Min.Y && center <= box.Max.Y {
+				last.Words = append(last.Words, word)
+				continue
+			}
+		}
+
+		lines = append(lines, Line{Words: []Word{word}})
+	}
+
+	for _, line := range lines {
+		sort.SliceStable(line.Words, func(i, j int) bool {
+			return line.Words[i].Box.Min.X < line.Words[j].Box.Min.X
+		})
+	}
+
+	return lines
+}
@@ -0,0 +1,70 @@
+package imageocr
+
+import (
+	"context"
+	"image"
+	"testing"
+)
+
+func word(x, y int, text string, confidence float64) Word {
+	return Word{Text: text, Box: image.Rect(x, y, x+20*len([]rune(text)), y+30), Confidence: confidence}
+}
+
+func TestReadingOrderColumns(t *testing.T) {
+	words := []Word{
+		word(800, 100, "Ponuka", 90),
+		word(40, 100, "Menu", 90),
+		word(140, 100, "1", 90),
+		word(520, 102, "6,90", 90),
+		word(620, 102, "€", 90),
+		word(800, 150, "Caesar", 90),
+		word(960, 150, "šalát", 90),
+		word(40, 150, "Kuracie", 90),
+		word(200, 150, "prsia", 90),
+		word(40, 20, "Denné", 90),
+		word(160, 20, "menu", 90),
+		word(280, 20, "a", 90),
+		word(320, 20, "ponuka", 90),
+		word(460, 20, "týždňa", 90),
+		word(600, 20, "reštaurácie", 90),
+		word(840, 20, "Lindy", 90),
+	}
+
+	text := LinesText(ReadingOrder(words))
+
+	expected := "Denné menu a ponuka týždňa reštaurácie Lindy\n" +
+		"Menu 1 6,90 €\nKuracie prsia\n" +
+		"Ponuka\nCaesar šalát"
+
+	if text != expected {
+		t.Errorf("unexpected text %q", text)
+	}
+}
+
+func TestLineCellsAndConfidence(t *testing.T) {
+	line := Line{Words: []Word{
+		word(40, 100, "Menu", 90),
+		word(140, 100, "1", 0),
+		word(520, 100, "6,90", 90),
+	}}
+
+	cells := line.Cells()
+	if len(cells) != 2 || cells[0].Text() != "Menu 1" || cells[1].Text() != "6,90" {
+		t.Errorf("unexpected cells %+v", cells)
+	}
+
+	if confidence := line.Confidence(); confidence != 80 {
+		t.Errorf("unexpected confidence %f", confidence)
+	}
+}
+
+func TestDevImageOcrLines(t *testing.T) {
+	lines, err := DevImageOcr{ImgText: "Menu 1 6,90 €\nKuracie prsia"}.ParseImageLines(context.Background(), nil, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(lines) != 2 || lines[0].Text() != "Menu 1 6,90 €" || lines[1].Confidence() != 100 {
+		t.Errorf("unexpected lines %+v", lines)
+	}
+}
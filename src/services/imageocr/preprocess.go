@@ -0,0 +1,341 @@
+package imageocr
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/disintegration/imaging"
+)
+
+// Pipeline is a sequence of image preprocessing steps applied before OCR. Steps
+// are written as "name" or "name=value", e.g. "scale=4" or "threshold-otsu".
+type Pipeline []string
+
+// DefaultPipeline is used when no pipeline is configured for a source.
+var DefaultPipeline = Pipeline{"scale=4", "contrast=10", "sharpen=4"}
+
+type stepFunc func(img image.Image, value float64) image.Image
+
+type stepDef struct {
+	apply        stepFunc
+	defaultValue float64
+}
+
+var steps = map[string]stepDef{
+	"grayscale":          {apply: grayscaleStep},
+	"scale":              {apply: scaleStep, defaultValue: 2},
+	"contrast":           {apply: contrastStep, defaultValue: 10},
+	"sharpen":            {apply: sharpenStep, defaultValue: 1},
+	"denoise":            {apply: denoiseStep, defaultValue: 1},
+	"threshold-otsu":     {apply: otsuStep},
+	"threshold-adaptive": {apply: adaptiveThresholdStep, defaultValue: 31},
+	"deskew":             {apply: deskewStep, defaultValue: 5},
+	"crop":               {apply: cropStep, defaultValue: 16},
+}
+
+// Validate reports unknown steps and malformed values, so a typo in the config
+// fails at startup and not on the first menu image.
+func (pipeline Pipeline) Validate() error {
+	for _, step := range pipeline {
+		if _, _, err := parseStep(step); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (pipeline Pipeline) Apply(img image.Image) (image.Image, error) {
+	if pipeline == nil {
+		pipeline = DefaultPipeline
+	}
+
+	for _, step := range pipeline {
+		def, value, err := parseStep(step)
+		if err != nil {
+			return nil, err
+		}
+
+		img = def.apply(img, value)
+	}
+
+	return img, nil
+}
+
+func (pipeline Pipeline) String() string {
+	return strings.Join(pipeline, ",")
+}
+
+func parseStep(step string) (stepDef, float64, error) {
+	stepName, rawValue, hasValue := strings.Cut(strings.TrimSpace(step), "=")
+
+	def, ok := steps[stepName]
+	if !ok {
+		return stepDef{}, 0, fmt.Errorf("unknown preprocessing step \"%s\"", stepName)
+	}
+
+	value := def.defaultValue
+
+	if hasValue {
+		parsed, err := strconv.ParseFloat(rawValue, 64)
+		if err != nil || parsed <= 0 {
+			return stepDef{}, 0, fmt.Errorf("invalid value \"%s\" of preprocessing step \"%s\"", rawValue, stepName)
+		}
+
+		value = parsed
+	}
+
+	return def, value, nil
+}
+
+func grayscaleStep(img image.Image, _ float64) image.Image {
+	return toGray(img)
+}
+
+func scaleStep(img image.Image, factor float64) image.Image {
+	bounds := img.Bounds()
+
+	return imaging.Resize(img, int(float64(bounds.Dx())*factor), int(float64(bounds.Dy())*factor), imaging.Lanczos)
+}
+
+func contrastStep(img image.Image, percentage float64) image.Image {
+	return imaging.AdjustContrast(img, percentage)
+}
+
+func sharpenStep(img image.Image, sigma float64) image.Image {
+	return imaging.Sharpen(img, sigma)
+}
+
+// denoiseStep applies a median filter with the given radius, which removes
+// speckles from JPEG compression without blurring letter edges.
+func denoiseStep(img image.Image, radius float64) image.Image {
+	gray := toGray(img)
+	bounds := gray.Bounds()
+	result := image.NewGray(bounds)
+	r := int(radius)
+
+	window := make([]uint8, 0, (2*r+1)*(2*r+1))
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			window = window[:0]
+
+			for wy := max(y-r, bounds.Min.Y); wy <= min(y+r, bounds.Max.Y-1); wy++ {
+				for wx := max(x-r, bounds.Min.X); wx <= min(x+r, bounds.Max.X-1); wx++ {
+					window = append(window, gray.GrayAt(wx, wy).Y)
+				}
+			}
+
+			sort.Slice(window, func(i, j int) bool { return window[i] < window[j] })
+
+			result.SetGray(x, y, color.Gray{Y: window[len(window)/2]})
+		}
+	}
+
+	return result
+}
+
+// otsuStep binarizes the image with a global threshold which best separates the
+// text and background brightness.
+func otsuStep(img image.Image, _ float64) image.Image {
+	gray := toGray(img)
+	threshold := otsuThreshold(gray)
+
+	return binarize(gray, func(x, y int) uint8 {
+		return threshold
+	})
+}
+
+// adaptiveThresholdStep binarizes every pixel against the mean of its window,
+// which copes with shadows and uneven lighting of photographed menus.
+func adaptiveThresholdStep(img image.Image, windowSize float64) image.Image {
+	const offset = 10
+
+	gray := toGray(img)
+	bounds := gray.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	half := int(windowSize) / 2
+
+	// The integral image holds the sum of all pixels above and to the left.
+	integral := make([]int64, (width+1)*(height+1))
+
+	for y := 0; y < height; y++ {
+		var rowSum int64
+
+		for x := 0; x < width; x++ {
+			rowSum += int64(gray.GrayAt(bounds.Min.X+x, bounds.Min.Y+y).Y)
+			integral[(y+1)*(width+1)+x+1] = integral[y*(width+1)+x+1] + rowSum
+		}
+	}
+
+	return binarize(gray, func(x, y int) uint8 {
+		x0, y0 := max(x-half, 0), max(y-half, 0)
+		x1, y1 := min(x+half+1, width), min(y+half+1, height)
+
+		sum := integral[y1*(width+1)+x1] - integral[y0*(width+1)+x1] - integral[y1*(width+1)+x0] + integral[y0*(width+1)+x0]
+		mean := sum / int64((x1-x0)*(y1-y0))
+
+		return uint8(max(mean-offset, 0))
+	})
+}
+
+// deskewStep straightens the image by the angle, at most maxAngle degrees, at
+// which the rows of dark pixels are the most uneven, i.e. aligned with text lines.
+func deskewStep(img image.Image, maxAngle float64) image.Image {
+	const step = 0.25
+
+	sample := toGray(imaging.Fit(img, 800, 800, imaging.Box))
+	threshold := otsuThreshold(sample)
+	bounds := sample.Bounds()
+
+	type point struct{ x, y float64 }
+
+	var dark []point
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if sample.GrayAt(x, y).Y < threshold {
+				dark = append(dark, point{float64(x), float64(y)})
+			}
+		}
+	}
+
+	if len(dark) == 0 {
+		return img
+	}
+
+	bestAngle, bestScore := 0.0, -1.0
+
+	for angle := -maxAngle; angle <= maxAngle; angle += step {
+		sin, cos := math.Sincos(angle * math.Pi / 180)
+		rows := make(map[int]float64)
+
+		for _, p := range dark {
+			rows[int(math.Round(p.y*cos-p.x*sin))]++
+		}
+
+		var score float64
+		for _, count := range rows {
+			score += count * count
+		}
+
+		if score > bestScore {
+			bestAngle, bestScore = angle, score
+		}
+	}
+
+	if bestAngle == 0 {
+		return img
+	}
+
+	return imaging.Rotate(img, bestAngle, color.White)
+}
+
+// cropStep crops the image to the dark content with the given margin in pixels.
+func cropStep(img image.Image, margin float64) image.Image {
+	gray := toGray(img)
+	threshold := otsuThreshold(gray)
+	bounds := gray.Bounds()
+
+	content := image.Rectangle{}
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if gray.GrayAt(x, y).Y < threshold {
+				content = content.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
+	}
+
+	if content.Empty() {
+		return img
+	}
+
+	content = content.Inset(-int(margin)).Intersect(bounds)
+
+	return imaging.Crop(img, content)
+}
+
+func toGray(img image.Image) *image.Gray {
+	if gray, ok := img.(*image.Gray); ok {
+		return gray
+	}
+
+	gray := image.NewGray(img.Bounds())
+	draw.Draw(gray, gray.Bounds(), img, img.Bounds().Min, draw.Src)
+
+	return gray
+}
+
+// binarize turns pixels darker than their threshold black and the rest white. The
+// threshold function gets coordinates relative to the image origin.
+func binarize(gray *image.Gray, threshold func(x, y int) uint8) *image.Gray {
+	bounds := gray.Bounds()
+	result := image.NewGray(bounds)
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			value := uint8(255)
+			if gray.GrayAt(x, y).Y < threshold(x-bounds.Min.X, y-bounds.Min.Y) {
+				value = 0
+			}
+
+			result.SetGray(x, y, color.Gray{Y: value})
+		}
+	}
+
+	return result
+}
+
+func otsuThreshold(gray *image.Gray) uint8 {
+	var histogram [256]float64
+
+	bounds := gray.Bounds()
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			histogram[gray.GrayAt(x, y).Y]++
+		}
+	}
+
+	total := float64(bounds.Dx() * bounds.Dy())
+
+	var sum float64
+	for value, count := range histogram {
+		sum += float64(value) * count
+	}
+
+	var backgroundSum, backgroundCount, bestVariance float64
+	threshold := uint8(128)
+
+	for value, count := range histogram {
+		backgroundCount += count
+		if backgroundCount == 0 {
+			continue
+		}
+
+		foregroundCount := total - backgroundCount
+		if foregroundCount == 0 {
+			break
+		}
+
+		backgroundSum += float64(value) * count
+
+		backgroundMean := backgroundSum / backgroundCount
+		foregroundMean := (sum - backgroundSum) / foregroundCount
+
+		variance := backgroundCount * foregroundCount * (backgroundMean - foregroundMean) * (backgroundMean - foregroundMean)
+		if variance > bestVariance {
+			bestVariance = variance
+			threshold = uint8(value + 1)
+		}
+	}
+
+	return threshold
+}
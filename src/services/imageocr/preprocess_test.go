@@ -0,0 +1,85 @@
+package imageocr
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/disintegration/imaging"
+)
+
+// textImage draws dark horizontal bars imitating text lines on a light background.
+func textImage() *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, 200, 120))
+
+	for y := 0; y < 120; y++ {
+		for x := 0; x < 200; x++ {
+			value := uint8(220)
+			if x >= 40 && x < 160 && (y >= 30 && y < 40 || y >= 60 && y < 70 || y >= 90 && y < 100) {
+				value = 40
+			}
+
+			img.SetGray(x, y, color.Gray{Y: value})
+		}
+	}
+
+	return img
+}
+
+func TestPipelineValidate(t *testing.T) {
+	if err := (Pipeline{"grayscale", "scale=4", "threshold-otsu"}).Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if err := (Pipeline{"blur"}).Validate(); err == nil {
+		t.Error("expected unknown step to fail")
+	}
+
+	if err := (Pipeline{"scale=big"}).Validate(); err == nil {
+		t.Error("expected invalid value to fail")
+	}
+}
+
+func TestPipelineThresholdAndCrop(t *testing.T) {
+	for _, pipeline := range []Pipeline{{"threshold-otsu", "crop=5"}, {"threshold-adaptive=15", "crop=5"}} {
+		img, err := pipeline.Apply(textImage())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if bounds := img.Bounds(); bounds.Dx() != 130 || bounds.Dy() != 80 {
+			t.Errorf("pipeline %s: unexpected bounds %v", pipeline, bounds)
+		}
+
+		gray := toGray(img)
+		if gray.GrayAt(20, 10).Y != 0 || gray.GrayAt(20, 25).Y != 255 {
+			t.Errorf("pipeline %s: image isn't binarized", pipeline)
+		}
+	}
+}
+
+func TestPipelineDeskew(t *testing.T) {
+	rotated := imaging.Rotate(textImage(), 3, color.Gray{Y: 220})
+
+	img, err := Pipeline{"deskew=5", "threshold-otsu", "crop=1"}.Apply(rotated)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// A straightened image has the text bars spread over about 70 rows again,
+	// while the skewed one spans more than 75.
+	if height := img.Bounds().Dy(); height > 75 {
+		t.Errorf("image wasn't straightened, content height %d", height)
+	}
+}
+
+func TestPipelineDefault(t *testing.T) {
+	img, err := Pipeline(nil).Apply(textImage())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if bounds := img.Bounds(); bounds.Dx() != 800 || bounds.Dy() != 480 {
+		t.Errorf("unexpected bounds %v", bounds)
+	}
+}
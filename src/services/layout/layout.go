@@ -0,0 +1,296 @@
+package layout
+
+import (
+	"math"
+	"sort"
+)
+
+// Span is the horizontal extent of a piece of text, a PDF text run or an OCR
+// word, with its size, i.e. the font size or the height, to which gaps are
+// compared.
+type Span struct {
+	Start float64
+	End   float64
+	Size  float64
+}
+
+// Part is a piece of an input line in reading order. Line is the index of the
+// input line the items come from.
+type Part[T any] struct {
+	Line  int
+	Items []T
+}
+
+const (
+	// CellGap is the smallest gap between two pieces of text, relative to their
+	// size, that separates two table cells, e.g. a dish and its price.
+	CellGap = 1.5
+	// minColumnShare is the smallest width of a text column relative to the whole
+	// section. Narrower right-hand columns are table cells, e.g. prices.
+	minColumnShare = 0.25
+	maxColumnDepth = 4
+)
+
+// Cells splits a line, ordered from left to right, at gaps wider than CellGap
+// times the size of its largest text.
+func Cells[T any](line []T, span func(T) Span) [][]T {
+	size := 1.0
+
+	for _, item := range line {
+		size = math.Max(size, span(item).Size)
+	}
+
+	var cells [][]T
+	end := math.Inf(-1)
+
+	for index, item := range line {
+		itemSpan := span(item)
+
+		if index == 0 || itemSpan.Start-end > CellGap*size {
+			cells = append(cells, nil)
+		}
+
+		cells[len(cells)-1] = append(cells[len(cells)-1], item)
+		end = math.Max(end, itemSpan.End)
+	}
+
+	return cells
+}
+
+// ReadingOrder reads multi-column text column by column. The lines are ordered
+// from top to bottom and their items from left to right. Lines with a cell
+// crossing the gutter, e.g. headings, stay in place and end the section of
+// columns above them, so a heading above two columns stays above both of them.
+func ReadingOrder[T any](lines [][]T, span func(T) Span) []Part[T] {
+	parts := make([]part, len(lines))
+
+	for index, line := range lines {
+		parts[index] = newPart(index, Cells(line, span), span)
+	}
+
+	ordered := readingOrder(parts, 0)
+	result := make([]Part[T], 0, len(ordered))
+
+	for _, p := range ordered {
+		var items []T
+
+		for _, cell := range p.cells {
+			items = append(items, lines[p.line][cell.first:cell.last]...)
+		}
+
+		result = append(result, Part[T]{Line: p.line, Items: items})
+	}
+
+	return result
+}
+
+// cell is a range of items of an input line with their joint span.
+type cell struct {
+	first, last int
+	span        Span
+}
+
+type part struct {
+	line  int
+	cells []cell
+}
+
+func newPart[T any](line int, cells [][]T, span func(T) Span) part {
+	p := part{line: line}
+	first := 0
+
+	for _, items := range cells {
+		c := cell{first: first, last: first + len(items), span: Span{Start: math.Inf(1), End: math.Inf(-1)}}
+
+		for _, item := range items {
+			itemSpan := span(item)
+
+			c.span.Start = math.Min(c.span.Start, itemSpan.Start)
+			c.span.End = math.Max(c.span.End, itemSpan.End)
+			c.span.Size = math.Max(c.span.Size, itemSpan.Size)
+		}
+
+		p.cells = append(p.cells, c)
+		first = c.last
+	}
+
+	return p
+}
+
+// split divides the cells of the part by the gutter.
+func (p part) split(gutter float64) (left part, right part, crossing bool) {
+	left.line, right.line = p.line, p.line
+
+	for _, c := range p.cells {
+		switch {
+		case c.span.End <= gutter:
+			left.cells = append(left.cells, c)
+		case c.span.Start >= gutter:
+			right.cells = append(right.cells, c)
+		default:
+			crossing = true
+		}
+	}
+
+	return left, right, crossing
+}
+
+// span is the joint span of the cells.
+func (p part) span() Span {
+	result := Span{Start: math.Inf(1), End: math.Inf(-1)}
+
+	for _, c := range p.cells {
+		result.Start = math.Min(result.Start, c.span.Start)
+		result.End = math.Max(result.End, c.span.End)
+		result.Size = math.Max(result.Size, c.span.Size)
+	}
+
+	return result
+}
+
+func readingOrder(parts []part, depth int) []part {
+	if depth >= maxColumnDepth || len(parts) < 2 {
+		return parts
+	}
+
+	gutter, ok := findGutter(parts)
+	if !ok {
+		return parts
+	}
+
+	var ordered, left, right []part
+
+	flush := func() {
+		ordered = append(ordered, readingOrder(left, depth+1)...)
+		ordered = append(ordered, readingOrder(right, depth+1)...)
+		left, right = nil, nil
+	}
+
+	for _, p := range parts {
+		partLeft, partRight, crossing := p.split(gutter)
+
+		if crossing {
+			flush()
+			ordered = append(ordered, p)
+
+			continue
+		}
+
+		if len(partLeft.cells) > 0 {
+			left = append(left, partLeft)
+		}
+
+		if len(partRight.cells) > 0 {
+			right = append(right, partRight)
+		}
+	}
+
+	flush()
+
+	return ordered
+}
+
+// findGutter looks for an empty vertical band, at least a cell gap wide, between
+// the cells of lines with several cells. A price beside a dish also leaves such
+// a band, so the band after which most lines start at the same position, i.e. a
+// real column edge, wins and the widest one breaks ties.
+func findGutter(parts []part) (float64, bool) {
+	var spans []Span
+	var sizes []float64
+
+	left, right := math.Inf(1), math.Inf(-1)
+
+	for _, p := range parts {
+		if len(p.cells) < 2 {
+			continue
+		}
+
+		for _, c := range p.cells {
+			spans = append(spans, c.span)
+			sizes = append(sizes, c.span.Size)
+
+			left = math.Min(left, c.span.Start)
+			right = math.Max(right, c.span.End)
+		}
+	}
+
+	if len(spans) == 0 {
+		return 0, false
+	}
+
+	sort.Float64s(sizes)
+	minGap := CellGap * math.Max(sizes[len(sizes)/2], 1)
+
+	sort.Slice(spans, func(i, j int) bool {
+		return spans[i].Start < spans[j].Start
+	})
+
+	bestGutter, bestWidth, bestAligned := 0.0, 0.0, 0
+	covered := spans[0].End
+
+	for _, current := range spans[1:] {
+		if gap := current.Start - covered; gap >= minGap {
+			gutter := covered + gap/2
+
+			aligned, ok := columnsAround(parts, gutter, right-left)
+			if ok && (aligned > bestAligned || aligned == bestAligned && gap > bestWidth) {
+				bestGutter, bestWidth, bestAligned = gutter, gap, aligned
+			}
+		}
+
+		covered = math.Max(covered, current.End)
+	}
+
+	return bestGutter, bestWidth > 0
+}
+
+// columnsAround checks that the gutter separates two wide enough columns in at
+// least two lines and returns the number of lines whose right-hand part starts
+// at the column edge.
+func columnsAround(parts []part, gutter float64, sectionWidth float64) (int, bool) {
+	leftStart, leftEnd := math.Inf(1), math.Inf(-1)
+	rightStart, rightEnd := math.Inf(1), math.Inf(-1)
+
+	var rightSpans []Span
+	bothSides := 0
+
+	for _, p := range parts {
+		partLeft, partRight, crossing := p.split(gutter)
+
+		// Headings crossing the gutter are kept in place by readingOrder.
+		if crossing {
+			continue
+		}
+
+		if len(partLeft.cells) > 0 {
+			span := partLeft.span()
+
+			leftStart = math.Min(leftStart, span.Start)
+			leftEnd = math.Max(leftEnd, span.End)
+		}
+
+		if len(partRight.cells) > 0 {
+			span := partRight.span()
+
+			rightStart = math.Min(rightStart, span.Start)
+			rightEnd = math.Max(rightEnd, span.End)
+			rightSpans = append(rightSpans, span)
+		}
+
+		if len(partLeft.cells) > 0 && len(partRight.cells) > 0 {
+			bothSides++
+		}
+	}
+
+	aligned := 0
+
+	for _, span := range rightSpans {
+		if span.Start-rightStart <= span.Size {
+			aligned++
+		}
+	}
+
+	minWidth := minColumnShare * sectionWidth
+
+	return aligned, bothSides >= 2 && leftEnd-leftStart >= minWidth && rightEnd-rightStart >= minWidth
+}
@@ -0,0 +1,67 @@
+package layout
+
+import (
+	"strings"
+	"testing"
+)
+
+type word struct {
+	x    float64
+	text string
+}
+
+func wordSpan(w word) Span {
+	return Span{Start: w.x, End: w.x + 10*float64(len([]rune(w.text))), Size: 20}
+}
+
+func line(words ...word) []word {
+	return words
+}
+
+func partsText(parts []Part[word]) string {
+	texts := make([]string, len(parts))
+
+	for index, part := range parts {
+		var words []string
+
+		for _, w := range part.Items {
+			words = append(words, w.text)
+		}
+
+		texts[index] = strings.Join(words, " ")
+	}
+
+	return strings.Join(texts, "\n")
+}
+
+func TestCells(t *testing.T) {
+	cells := Cells(line(word{0, "Menu"}, word{50, "1"}, word{300, "6,90"}, word{350, "€"}), wordSpan)
+
+	if len(cells) != 2 || len(cells[0]) != 2 || cells[1][0].text != "6,90" {
+		t.Errorf("unexpected cells %+v", cells)
+	}
+}
+
+func TestReadingOrderNestedColumns(t *testing.T) {
+	lines := [][]word{
+		line(word{0, "Týždenná ponuka reštaurácie na celý týždeň od pondelka"}),
+		line(word{0, "Pondelok"}, word{300, "Streda"}),
+		line(word{0, "Guláš"}, word{150, "6,90"}, word{300, "Rizoto"}, word{450, "7,20"}),
+		line(word{0, "Utorok"}, word{300, "Štvrtok"}),
+		line(word{0, "Rezeň"}, word{150, "7,50"}, word{300, "Losos"}, word{450, "8,90"}),
+	}
+
+	parts := ReadingOrder(lines, wordSpan)
+
+	expected := "Týždenná ponuka reštaurácie na celý týždeň od pondelka\n" +
+		"Pondelok\nGuláš 6,90\nUtorok\nRezeň 7,50\n" +
+		"Streda\nRizoto 7,20\nŠtvrtok\nLosos 8,90"
+
+	if text := partsText(parts); text != expected {
+		t.Errorf("unexpected text %q", text)
+	}
+
+	if parts[2].Line != 2 || parts[6].Line != 2 {
+		t.Errorf("parts don't point to their source lines %+v", parts)
+	}
+}
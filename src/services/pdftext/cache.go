@@ -0,0 +1,42 @@
+package pdftext
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"menucko/services/resultcache"
+)
+
+const cachingPDFTextLogPrefix = "[PDF Cache]"
+
+// pdfCacheVersion is part of every cache key. Bump it when the extracted text for
+// the same PDF changes, e.g. after fixing a font encoding.
+const pdfCacheVersion = "1"
+
+// CachingPDFText serves text of PDFs it has already extracted from the cache.
+type CachingPDFText struct {
+	PDFText PDFText
+	Cache   resultcache.Cache
+}
+
+func (pdfText CachingPDFText) ExtractText(ctx context.Context, pdfBytes []byte) (string, error) {
+	return resultcache.Cached(pdfText.Cache, pdfText.key(pdfBytes, "text"), pdfText.log, func() (string, error) {
+		return pdfText.PDFText.ExtractText(ctx, pdfBytes)
+	})
+}
+
+func (pdfText CachingPDFText) ExtractRuns(ctx context.Context, pdfBytes []byte) ([]Run, error) {
+	return resultcache.Cached(pdfText.Cache, pdfText.key(pdfBytes, "runs"), pdfText.log, func() ([]Run, error) {
+		return pdfText.PDFText.ExtractRuns(ctx, pdfBytes)
+	})
+}
+
+func (CachingPDFText) key(pdfBytes []byte, kind string) string {
+	return resultcache.Key(pdfBytes, "pdftext", pdfCacheVersion, kind)
+}
+
+func (CachingPDFText) log(format string, v ...any) {
+	message := cachingPDFTextLogPrefix + " " + fmt.Sprintf(format, v...)
+
+	log.Println(message)
+}
@@ -0,0 +1,348 @@
+package pdftext
+
+import (
+	"bytes"
+	"math"
+)
+
+// matrix is an affine transformation [a b c d e f] as used by PDF.
+type matrix [6]float64
+
+var identity = matrix{1, 0, 0, 1, 0, 0}
+
+func (m matrix) multiply(n matrix) matrix {
+	return matrix{
+		m[0]*n[0] + m[1]*n[2],
+		m[0]*n[1] + m[1]*n[3],
+		m[2]*n[0] + m[3]*n[2],
+		m[2]*n[1] + m[3]*n[3],
+		m[4]*n[0] + m[5]*n[2] + n[4],
+		m[4]*n[1] + m[5]*n[3] + n[5],
+	}
+}
+
+type graphicsState struct {
+	ctm         matrix
+	font        *font
+	fontSize    float64
+	charSpacing float64
+	wordSpacing float64
+	hScale      float64
+	leading     float64
+	rise        float64
+}
+
+// interpreter executes content streams and collects the shown text as runs.
+type interpreter struct {
+	doc   *document
+	page  int
+	fonts map[any]*font
+	runs  []Run
+
+	state      graphicsState
+	stack      []graphicsState
+	textMatrix matrix
+	lineMatrix matrix
+}
+
+const maxFormDepth = 8
+
+func newInterpreter(doc *document, page int) *interpreter {
+	return &interpreter{
+		doc:   doc,
+		page:  page,
+		fonts: make(map[any]*font),
+		state: graphicsState{
+			ctm:    identity,
+			hScale: 1,
+		},
+	}
+}
+
+func (in *interpreter) run(content []byte, resources dict, depth int) {
+	l := &lexer{data: content}
+
+	var operands []any
+
+	for {
+		token, err := l.next()
+		if err != nil {
+			return
+		}
+
+		switch token := token.(type) {
+		case delimiter:
+			switch token {
+			case "[":
+				items, err := l.readArray()
+				if err != nil {
+					return
+				}
+
+				operands = append(operands, items)
+			case "<<":
+				items, err := l.readDict()
+				if err != nil {
+					return
+				}
+
+				operands = append(operands, items)
+			}
+		case keyword:
+			if token == "BI" {
+				skipInlineImage(l)
+			} else {
+				in.execute(string(token), operands, resources, depth)
+			}
+
+			operands = operands[:0]
+		default:
+			operands = append(operands, token)
+		}
+	}
+}
+
+// skipInlineImage moves past the binary image data which would confuse the lexer.
+func skipInlineImage(l *lexer) {
+	for l.pos < len(l.data) {
+		end := bytes.Index(l.data[l.pos:], []byte("EI"))
+		if end < 0 {
+			l.pos = len(l.data)
+			return
+		}
+
+		l.pos += end + 2
+
+		if end > 0 && isWhitespace(l.data[l.pos-3]) && (l.pos == len(l.data) || isWhitespace(l.data[l.pos]) || isDelimiter(l.data[l.pos])) {
+			return
+		}
+	}
+}
+
+func (in *interpreter) execute(operator string, operands []any, resources dict, depth int) {
+	number := func(index int) float64 {
+		if index >= len(operands) {
+			return 0
+		}
+
+		return in.doc.number(operands[index])
+	}
+
+	numbers := func() matrix {
+		var m matrix
+
+		for i := range m {
+			m[i] = number(i)
+		}
+
+		return m
+	}
+
+	switch operator {
+	case "q":
+		in.stack = append(in.stack, in.state)
+	case "Q":
+		if len(in.stack) > 0 {
+			in.state = in.stack[len(in.stack)-1]
+			in.stack = in.stack[:len(in.stack)-1]
+		}
+	case "cm":
+		if len(operands) == 6 {
+			in.state.ctm = numbers().multiply(in.state.ctm)
+		}
+	case "BT":
+		in.textMatrix = identity
+		in.lineMatrix = identity
+	case "Tf":
+		if len(operands) == 2 {
+			in.state.font = in.loadFont(resources, operands[0])
+			in.state.fontSize = number(1)
+		}
+	case "TL":
+		in.state.leading = number(0)
+	case "Tc":
+		in.state.charSpacing = number(0)
+	case "Tw":
+		in.state.wordSpacing = number(0)
+	case "Tz":
+		in.state.hScale = number(0) / 100
+	case "Ts":
+		in.state.rise = number(0)
+	case "Td":
+		in.moveLine(number(0), number(1))
+	case "TD":
+		in.state.leading = -number(1)
+		in.moveLine(number(0), number(1))
+	case "Tm":
+		if len(operands) == 6 {
+			in.textMatrix = numbers()
+			in.lineMatrix = in.textMatrix
+		}
+	case "T*":
+		in.moveLine(0, -in.state.leading)
+	case "Tj":
+		if len(operands) == 1 {
+			in.show(operands[0])
+		}
+	case "'":
+		in.moveLine(0, -in.state.leading)
+
+		if len(operands) == 1 {
+			in.show(operands[0])
+		}
+	case "\"":
+		if len(operands) == 3 {
+			in.state.wordSpacing = number(0)
+			in.state.charSpacing = number(1)
+			in.moveLine(0, -in.state.leading)
+			in.show(operands[2])
+		}
+	case "TJ":
+		if len(operands) == 1 {
+			in.showArray(in.doc.array(operands[0]))
+		}
+	case "Do":
+		if len(operands) == 1 && depth < maxFormDepth {
+			in.drawForm(resources, operands[0], depth)
+		}
+	}
+}
+
+func (in *interpreter) moveLine(tx, ty float64) {
+	in.lineMatrix = matrix{1, 0, 0, 1, tx, ty}.multiply(in.lineMatrix)
+	in.textMatrix = in.lineMatrix
+}
+
+func (in *interpreter) loadFont(resources dict, fontName any) *font {
+	fontRef := in.doc.dict(resources["Font"])[nameOf(fontName)]
+
+	// The operand may be of any type in a malformed content stream, only names
+	// and references are safe map keys.
+	key := any(nameOf(fontName))
+	if _, ok := fontRef.(ref); ok {
+		key = fontRef
+	}
+
+	if f, ok := in.fonts[key]; ok {
+		return f
+	}
+
+	f := in.doc.loadFont(in.doc.dict(fontRef))
+	in.fonts[key] = f
+
+	return f
+}
+
+func nameOf(object any) name {
+	n, _ := object.(name)
+
+	return n
+}
+
+func (in *interpreter) drawForm(resources dict, xObjectName any, depth int) {
+	form, ok := in.doc.resolve(in.doc.dict(resources["XObject"])[nameOf(xObjectName)]).(stream)
+	if !ok || form.dict["Subtype"] != name("Form") {
+		return
+	}
+
+	content, err := in.doc.decodeStream(form)
+	if err != nil {
+		return
+	}
+
+	formResources := in.doc.dict(form.dict["Resources"])
+	if formResources == nil {
+		formResources = resources
+	}
+
+	saved := in.state
+	savedText, savedLine := in.textMatrix, in.lineMatrix
+
+	if m := in.doc.array(form.dict["Matrix"]); len(m) == 6 {
+		var formMatrix matrix
+
+		for i := range formMatrix {
+			formMatrix[i] = in.doc.number(m[i])
+		}
+
+		in.state.ctm = formMatrix.multiply(in.state.ctm)
+	}
+
+	in.run(content, formResources, depth+1)
+
+	in.state = saved
+	in.textMatrix, in.lineMatrix = savedText, savedLine
+}
+
+func (in *interpreter) showArray(items array) {
+	for _, item := range items {
+		switch item := in.doc.resolve(item).(type) {
+		case []byte:
+			in.show(item)
+		case int64, float64:
+			adjustment := in.doc.number(item) / 1000 * in.state.fontSize * in.state.hScale
+			in.textMatrix = matrix{1, 0, 0, 1, -adjustment, 0}.multiply(in.textMatrix)
+		}
+	}
+}
+
+func (in *interpreter) show(operand any) {
+	data, ok := operand.([]byte)
+	if !ok || in.state.font == nil {
+		return
+	}
+
+	for _, g := range in.state.font.decode(data) {
+		start := in.textOrigin()
+
+		advance := g.width*in.state.fontSize + in.state.charSpacing
+		if g.space {
+			advance += in.state.wordSpacing
+		}
+
+		in.textMatrix = matrix{1, 0, 0, 1, advance * in.state.hScale, 0}.multiply(in.textMatrix)
+
+		if g.text == "" {
+			continue
+		}
+
+		end := in.textOrigin()
+
+		in.addRun(Run{
+			Page:     in.page,
+			X:        start[4],
+			Y:        start[5],
+			Width:    math.Hypot(end[4]-start[4], end[5]-start[5]),
+			FontSize: math.Hypot(start[2], start[3]),
+			Text:     g.text,
+		})
+	}
+}
+
+// textOrigin returns the text rendering matrix, its translation is the glyph
+// origin in user space and its vertical scale the effective font size.
+func (in *interpreter) textOrigin() matrix {
+	return matrix{in.state.fontSize * in.state.hScale, 0, 0, in.state.fontSize, 0, in.state.rise}.
+		multiply(in.textMatrix).
+		multiply(in.state.ctm)
+}
+
+// addRun appends the glyph to the previous run when it directly follows it.
+func (in *interpreter) addRun(run Run) {
+	if len(in.runs) > 0 {
+		last := &in.runs[len(in.runs)-1]
+		tolerance := 0.1 * math.Max(last.FontSize, 1)
+
+		if last.Page == run.Page &&
+			math.Abs(last.Y-run.Y) < tolerance &&
+			math.Abs(last.FontSize-run.FontSize) < tolerance &&
+			math.Abs(last.X+last.Width-run.X) < tolerance {
+			last.Text += run.Text
+			last.Width = run.X + run.Width - last.X
+
+			return
+		}
+	}
+
+	in.runs = append(in.runs, run)
+}
@@ -0,0 +1,338 @@
+package pdftext
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/md5"
+	"crypto/rc4"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+	"hash"
+)
+
+// passwordPadding pads passwords to 32 bytes in the standard security handler.
+var passwordPadding = []byte{
+	0x28, 0xbf, 0x4e, 0x5e, 0x4e, 0x75, 0x8a, 0x41, 0x64, 0x00, 0x4e, 0x56, 0xff, 0xfa, 0x01, 0x08,
+	0x2e, 0x2e, 0x00, 0xb6, 0xd0, 0x68, 0x3e, 0x80, 0x2f, 0x0c, 0xa9, 0xfe, 0x64, 0x53, 0x69, 0x7a,
+}
+
+const (
+	cryptIdentity = name("Identity")
+	cryptRC4      = name("V2")
+	cryptAES128   = name("AESV2")
+	cryptAES256   = name("AESV3")
+)
+
+// decryptor reads documents of the standard security handler, which open without
+// a password. Menus are often "protected" against editing this way, only the owner
+// password is set and any viewer opens them. Documents needing a user password
+// are rejected with ErrEncrypted.
+type decryptor struct {
+	key           []byte
+	streamMethod  name
+	stringMethod  name
+	skipMetadata  bool
+	encryptRefNum int
+}
+
+func newDecryptor(doc *document) (*decryptor, error) {
+	encrypt := doc.dict(doc.trailer["Encrypt"])
+	if encrypt == nil {
+		return nil, fmt.Errorf("%w: encryption dictionary not found", ErrEncrypted)
+	}
+
+	if filter := doc.resolve(encrypt["Filter"]); filter != name("Standard") {
+		return nil, fmt.Errorf("%w: security handler \"%v\" is not supported", ErrEncrypted, filter)
+	}
+
+	d := &decryptor{encryptRefNum: -1}
+
+	if r, ok := doc.trailer["Encrypt"].(ref); ok {
+		d.encryptRefNum = r.num
+	}
+
+	version := int(doc.number(encrypt["V"]))
+	revision := int(doc.number(encrypt["R"]))
+	owner := stringOf(doc.resolve(encrypt["O"]))
+	user := stringOf(doc.resolve(encrypt["U"]))
+
+	encryptMetadata, ok := doc.resolve(encrypt["EncryptMetadata"]).(bool)
+	d.skipMetadata = ok && !encryptMetadata
+
+	keyLength := 5
+	if length := int(doc.number(encrypt["Length"])); version >= 2 && length >= 40 {
+		keyLength = length / 8
+	}
+
+	switch version {
+	case 1, 2:
+		d.streamMethod, d.stringMethod = cryptRC4, cryptRC4
+	case 4, 5:
+		filters := doc.dict(encrypt["CF"])
+
+		d.streamMethod = cryptFilterMethod(doc, filters, encrypt["StmF"])
+		d.stringMethod = cryptFilterMethod(doc, filters, encrypt["StrF"])
+	default:
+		return nil, fmt.Errorf("%w: encryption version %d is not supported", ErrEncrypted, version)
+	}
+
+	if version == 4 && (d.streamMethod == cryptAES128 || d.stringMethod == cryptAES128) {
+		keyLength = 16
+	}
+
+	var err error
+
+	if version == 5 {
+		d.key, err = aes256Key(revision, user, stringOf(doc.resolve(encrypt["UE"])))
+	} else {
+		permissions := uint32(int32(doc.number(encrypt["P"])))
+		d.key, err = rc4Key(revision, keyLength, owner, user, permissions, doc.fileID(), d.skipMetadata)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+func cryptFilterMethod(doc *document, filters dict, filterName any) name {
+	filterName = doc.resolve(filterName)
+	if filterName == nil || filterName == cryptIdentity {
+		return cryptIdentity
+	}
+
+	method, _ := doc.resolve(doc.dict(filters[nameOf(filterName)])["CFM"]).(name)
+	if method == name("None") || len(method) == 0 {
+		return cryptIdentity
+	}
+
+	return method
+}
+
+func stringOf(object any) []byte {
+	value, _ := object.([]byte)
+
+	return value
+}
+
+func (doc *document) fileID() []byte {
+	ids := doc.array(doc.trailer["ID"])
+	if len(ids) == 0 {
+		return nil
+	}
+
+	return stringOf(doc.resolve(ids[0]))
+}
+
+// rc4Key computes the file key of revisions 2 to 4 for the empty user password
+// and checks it against the "/U" entry.
+func rc4Key(revision int, keyLength int, owner []byte, user []byte, permissions uint32, fileID []byte, skipMetadata bool) ([]byte, error) {
+	if len(owner) < 32 || len(user) < 16 || keyLength > 16 {
+		return nil, fmt.Errorf("%w: encryption dictionary is malformed", ErrEncrypted)
+	}
+
+	digest := md5.New()
+	digest.Write(passwordPadding)
+	digest.Write(owner[:32])
+	_ = binary.Write(digest, binary.LittleEndian, permissions)
+	digest.Write(fileID)
+
+	if revision >= 4 && skipMetadata {
+		digest.Write([]byte{0xff, 0xff, 0xff, 0xff})
+	}
+
+	key := digest.Sum(nil)
+
+	if revision >= 3 {
+		for i := 0; i < 50; i++ {
+			sum := md5.Sum(key[:keyLength])
+			key = sum[:]
+		}
+	}
+
+	key = key[:keyLength]
+
+	var check []byte
+
+	if revision == 2 {
+		check = rc4Crypt(key, passwordPadding)
+	} else {
+		sum := md5.Sum(append(append([]byte{}, passwordPadding...), fileID...))
+		check = sum[:]
+
+		for i := 0; i < 20; i++ {
+			roundKey := make([]byte, len(key))
+			for j := range key {
+				roundKey[j] = key[j] ^ byte(i)
+			}
+
+			check = rc4Crypt(roundKey, check)
+		}
+	}
+
+	if !bytes.Equal(check[:16], user[:16]) {
+		return nil, fmt.Errorf("%w: document needs a user password", ErrEncrypted)
+	}
+
+	return key, nil
+}
+
+// aes256Key validates the empty user password against "/U" and decrypts the file
+// key from "/UE", revision 5 hashes with SHA-256 only, revision 6 with algorithm 2.B.
+func aes256Key(revision int, user []byte, userKey []byte) ([]byte, error) {
+	if len(user) < 48 || len(userKey) != 32 {
+		return nil, fmt.Errorf("%w: encryption dictionary is malformed", ErrEncrypted)
+	}
+
+	passwordHash := func(salt []byte) []byte {
+		if revision == 5 {
+			sum := sha256.Sum256(salt)
+			return sum[:]
+		}
+
+		return hardenedHash(salt)
+	}
+
+	if !bytes.Equal(passwordHash(user[32:40]), user[:32]) {
+		return nil, fmt.Errorf("%w: document needs a user password", ErrEncrypted)
+	}
+
+	block, err := aes.NewCipher(passwordHash(user[40:48]))
+	if err != nil {
+		return nil, err
+	}
+
+	key := make([]byte, 32)
+	cipher.NewCBCDecrypter(block, make([]byte, aes.BlockSize)).CryptBlocks(key, userKey)
+
+	return key, nil
+}
+
+// hardenedHash is the hash of algorithm 2.B of ISO 32000-2 for the empty password.
+func hardenedHash(salt []byte) []byte {
+	sum := sha256.Sum256(salt)
+	key := sum[:]
+
+	for round := 0; ; round++ {
+		block := bytes.Repeat(key, 64)
+
+		aesBlock, _ := aes.NewCipher(key[:16])
+		encrypted := make([]byte, len(block))
+		cipher.NewCBCEncrypter(aesBlock, key[16:32]).CryptBlocks(encrypted, block)
+
+		var remainder int
+		for _, b := range encrypted[:16] {
+			remainder += int(b)
+		}
+
+		var digest hash.Hash
+
+		switch remainder % 3 {
+		case 0:
+			digest = sha256.New()
+		case 1:
+			digest = sha512.New384()
+		default:
+			digest = sha512.New()
+		}
+
+		digest.Write(encrypted)
+		key = digest.Sum(nil)
+
+		if round >= 63 && int(encrypted[len(encrypted)-1]) <= round+1-32 {
+			break
+		}
+	}
+
+	return key[:32]
+}
+
+func rc4Crypt(key []byte, data []byte) []byte {
+	c, err := rc4.NewCipher(key)
+	if err != nil {
+		return data
+	}
+
+	result := make([]byte, len(data))
+	c.XORKeyStream(result, data)
+
+	return result
+}
+
+// decryptObject decrypts the strings and the stream data of the object with the number.
+func (d *decryptor) decryptObject(object any, num int, gen int) any {
+	switch value := object.(type) {
+	case []byte:
+		return d.decrypt(value, d.stringMethod, num, gen)
+	case array:
+		for i, item := range value {
+			value[i] = d.decryptObject(item, num, gen)
+		}
+	case dict:
+		for key, item := range value {
+			value[key] = d.decryptObject(item, num, gen)
+		}
+	case stream:
+		if value.dict["Type"] == name("XRef") || d.skipMetadata && value.dict["Type"] == name("Metadata") {
+			return value
+		}
+
+		d.decryptObject(value.dict, num, gen)
+		value.data = d.decrypt(value.data, d.streamMethod, num, gen)
+
+		return value
+	}
+
+	return object
+}
+
+func (d *decryptor) decrypt(data []byte, method name, num int, gen int) []byte {
+	switch method {
+	case cryptRC4:
+		return rc4Crypt(d.objectKey(num, gen, false), data)
+	case cryptAES128:
+		return aesDecrypt(d.objectKey(num, gen, true), data)
+	case cryptAES256:
+		return aesDecrypt(d.key, data)
+	}
+
+	return data
+}
+
+// objectKey derives the key of one object from the file key.
+func (d *decryptor) objectKey(num int, gen int, aes bool) []byte {
+	digest := md5.New()
+	digest.Write(d.key)
+	digest.Write([]byte{byte(num), byte(num >> 8), byte(num >> 16), byte(gen), byte(gen >> 8)})
+
+	if aes {
+		digest.Write([]byte("sAlT"))
+	}
+
+	return digest.Sum(nil)[:min(len(d.key)+5, 16)]
+}
+
+// aesDecrypt decrypts CBC data prefixed with its IV and removes the padding.
+// Damaged data is returned as far as it can be decrypted.
+func aesDecrypt(key []byte, data []byte) []byte {
+	block, err := aes.NewCipher(key)
+	if err != nil || len(data) < 2*aes.BlockSize {
+		return nil
+	}
+
+	content := data[aes.BlockSize:]
+	content = content[:len(content)-len(content)%aes.BlockSize]
+
+	result := make([]byte, len(content))
+	cipher.NewCBCDecrypter(block, data[:aes.BlockSize]).CryptBlocks(result, content)
+
+	if padding := int(result[len(result)-1]); padding >= 1 && padding <= aes.BlockSize && padding <= len(result) {
+		result = result[:len(result)-padding]
+	}
+
+	return result
+}
@@ -0,0 +1,334 @@
+package pdftext
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// ErrEncrypted is returned for documents, which don't open without a password.
+var ErrEncrypted = errors.New("PDF is encrypted with a password or an unsupported method")
+var ErrInvalidPDF = errors.New("invalid PDF")
+
+var objectHeaderRe = regexp.MustCompile(`(\d+)\s+(\d+)\s+obj\b`)
+
+// document holds all objects of a PDF. Instead of relying on the cross-reference table,
+// which is often broken in generated menus, the objects are found by scanning the file
+// for "num gen obj" headers. Later definitions win, which matches incremental updates.
+type document struct {
+	objects map[int]any
+	trailer dict
+}
+
+func parseDocument(data []byte) (*document, error) {
+	if !bytes.HasPrefix(bytes.TrimLeft(data, " \t\r\n\x00"), []byte("%PDF-")) {
+		return nil, fmt.Errorf("%w: missing \"%%PDF-\" header", ErrInvalidPDF)
+	}
+
+	doc := &document{
+		objects: make(map[int]any),
+	}
+
+	var objectStreams []int
+	generations := make(map[int]int)
+	skipUntil := 0
+
+	for _, match := range objectHeaderRe.FindAllSubmatchIndex(data, -1) {
+		if match[0] < skipUntil {
+			continue
+		}
+
+		if match[0] > 0 && !isWhitespace(data[match[0]-1]) && !isDelimiter(data[match[0]-1]) {
+			continue
+		}
+
+		num, _ := strconv.Atoi(string(data[match[2]:match[3]]))
+		gen, _ := strconv.Atoi(string(data[match[4]:match[5]]))
+
+		l := &lexer{data: data, pos: match[1]}
+
+		object, err := l.readObject()
+		if err != nil {
+			continue
+		}
+
+		if objectDict, ok := object.(dict); ok {
+			if s, end, ok := readStream(l, objectDict); ok {
+				object = s
+				skipUntil = end
+
+				if objectDict["Type"] == name("ObjStm") {
+					objectStreams = append(objectStreams, num)
+				}
+
+				if objectDict["Type"] == name("XRef") {
+					doc.trailer = objectDict
+				}
+			}
+		}
+
+		doc.objects[num] = object
+		generations[num] = gen
+	}
+
+	if trailerPos := bytes.LastIndex(data, []byte("trailer")); trailerPos >= 0 {
+		l := &lexer{data: data, pos: trailerPos + len("trailer")}
+
+		if trailer, err := l.readObject(); err == nil {
+			if trailerDict, ok := trailer.(dict); ok && trailerDict["Root"] != nil {
+				doc.trailer = trailerDict
+			}
+		}
+	}
+
+	if doc.trailer == nil {
+		doc.trailer = dict{}
+	}
+
+	if doc.trailer["Encrypt"] != nil {
+		decryptor, err := newDecryptor(doc)
+		if err != nil {
+			return nil, err
+		}
+
+		for num, object := range doc.objects {
+			if num != decryptor.encryptRefNum {
+				doc.objects[num] = decryptor.decryptObject(object, num, generations[num])
+			}
+		}
+	}
+
+	// Objects in object streams are encrypted as a part of the stream.
+	for _, num := range objectStreams {
+		if s, ok := doc.objects[num].(stream); ok {
+			doc.loadObjectStream(s)
+		}
+	}
+
+	if doc.catalog() == nil {
+		return nil, fmt.Errorf("%w: document catalog not found", ErrInvalidPDF)
+	}
+
+	return doc, nil
+}
+
+// readStream reads the stream data following the dictionary, if there is any.
+// When "/Length" is missing or wrong, the data ends at the "endstream" keyword.
+func readStream(l *lexer, streamDict dict) (stream, int, bool) {
+	pos := l.pos
+
+	token, err := l.next()
+	if err != nil || token != keyword("stream") {
+		l.pos = pos
+		return stream{}, 0, false
+	}
+
+	start := l.pos
+	if start < len(l.data) && l.data[start] == '\r' {
+		start++
+	}
+
+	if start < len(l.data) && l.data[start] == '\n' {
+		start++
+	}
+
+	if length, ok := streamDict["Length"].(int64); ok && length >= 0 && start+int(length) <= len(l.data) {
+		end := start + int(length)
+
+		rest := bytes.TrimLeft(l.data[end:min(end+32, len(l.data))], " \t\r\n")
+		if bytes.HasPrefix(rest, []byte("endstream")) {
+			return stream{dict: streamDict, data: l.data[start:end]}, end, true
+		}
+	}
+
+	end := bytes.Index(l.data[start:], []byte("endstream"))
+	if end < 0 {
+		return stream{dict: streamDict, data: l.data[start:]}, len(l.data), true
+	}
+
+	data := l.data[start : start+end]
+	data = bytes.TrimSuffix(data, []byte("\n"))
+	data = bytes.TrimSuffix(data, []byte("\r"))
+
+	return stream{dict: streamDict, data: data}, start + end, true
+}
+
+// loadObjectStream adds objects compressed in an object stream. Objects defined
+// directly in the file take precedence.
+func (doc *document) loadObjectStream(s stream) {
+	data, err := doc.decodeStream(s)
+	if err != nil {
+		return
+	}
+
+	count, _ := doc.resolve(s.dict["N"]).(int64)
+	first, _ := doc.resolve(s.dict["First"]).(int64)
+
+	if first <= 0 || int(first) > len(data) {
+		return
+	}
+
+	header := &lexer{data: data[:first]}
+
+	for i := 0; i < int(count); i++ {
+		numToken, err := header.next()
+		if err != nil {
+			return
+		}
+
+		offsetToken, err := header.next()
+		if err != nil {
+			return
+		}
+
+		num, ok := numToken.(int64)
+		offset, ok2 := offsetToken.(int64)
+
+		if !ok || !ok2 || int(first+offset) >= len(data) {
+			continue
+		}
+
+		if _, exists := doc.objects[int(num)]; exists {
+			continue
+		}
+
+		l := &lexer{data: data, pos: int(first + offset)}
+
+		object, err := l.readObject()
+		if err != nil {
+			continue
+		}
+
+		doc.objects[int(num)] = object
+	}
+}
+
+func (doc *document) resolve(object any) any {
+	for depth := 0; depth < 16; depth++ {
+		r, ok := object.(ref)
+		if !ok {
+			return object
+		}
+
+		object = doc.objects[r.num]
+	}
+
+	return nil
+}
+
+func (doc *document) dict(object any) dict {
+	switch object := doc.resolve(object).(type) {
+	case dict:
+		return object
+	case stream:
+		return object.dict
+	}
+
+	return nil
+}
+
+func (doc *document) array(object any) array {
+	items, _ := doc.resolve(object).(array)
+
+	return items
+}
+
+func (doc *document) number(object any) float64 {
+	switch value := doc.resolve(object).(type) {
+	case int64:
+		return float64(value)
+	case float64:
+		return value
+	}
+
+	return 0
+}
+
+func (doc *document) catalog() dict {
+	if catalog := doc.dict(doc.trailer["Root"]); catalog != nil {
+		return catalog
+	}
+
+	for _, object := range doc.objects {
+		if objectDict, ok := object.(dict); ok && objectDict["Type"] == name("Catalog") {
+			return objectDict
+		}
+	}
+
+	return nil
+}
+
+type page struct {
+	dict      dict
+	resources dict
+}
+
+func (doc *document) pages() []page {
+	var pages []page
+
+	visited := make(map[int]bool)
+
+	var walk func(node any, resources dict, depth int)
+	walk = func(node any, resources dict, depth int) {
+		if r, ok := node.(ref); ok {
+			if visited[r.num] {
+				return
+			}
+
+			visited[r.num] = true
+		}
+
+		nodeDict := doc.dict(node)
+		if nodeDict == nil || depth > 32 {
+			return
+		}
+
+		if nodeResources := doc.dict(nodeDict["Resources"]); nodeResources != nil {
+			resources = nodeResources
+		}
+
+		if kids := doc.array(nodeDict["Kids"]); nodeDict["Type"] != name("Page") && kids != nil {
+			for _, kid := range kids {
+				walk(kid, resources, depth+1)
+			}
+
+			return
+		}
+
+		pages = append(pages, page{dict: nodeDict, resources: resources})
+	}
+
+	walk(doc.catalog()["Pages"], nil, 0)
+
+	return pages
+}
+
+func (doc *document) pageContent(p page) []byte {
+	var content bytes.Buffer
+
+	contents := doc.resolve(p.dict["Contents"])
+
+	streams := array{contents}
+	if contentsArray, ok := contents.(array); ok {
+		streams = contentsArray
+	}
+
+	for _, item := range streams {
+		s, ok := doc.resolve(item).(stream)
+		if !ok {
+			continue
+		}
+
+		data, err := doc.decodeStream(s)
+		if err != nil {
+			continue
+		}
+
+		content.Write(data)
+		content.WriteByte('\n')
+	}
+
+	return content.Bytes()
+}
@@ -0,0 +1,224 @@
+package pdftext
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/zlib"
+	"encoding/ascii85"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// maxDecodedSize limits a decoded stream, a small compressed stream can inflate
+// into gigabytes. Menus are far below it even with embedded fonts.
+const maxDecodedSize = 64 << 20
+
+var ErrStreamTooLarge = errors.New("PDF stream is too large when decoded")
+
+func (doc *document) decodeStream(s stream) ([]byte, error) {
+	data := s.data
+
+	filters := doc.resolve(s.dict["Filter"])
+	params := doc.resolve(s.dict["DecodeParms"])
+
+	filterList, ok := filters.(array)
+	if !ok {
+		filterList = array{filters}
+	}
+
+	paramList, ok := params.(array)
+	if !ok {
+		paramList = array{params}
+	}
+
+	for index, filter := range filterList {
+		filter = doc.resolve(filter)
+		if filter == nil {
+			continue
+		}
+
+		var filterParams dict
+		if index < len(paramList) {
+			filterParams = doc.dict(paramList[index])
+		}
+
+		var err error
+
+		switch filter {
+		case name("FlateDecode"), name("Fl"):
+			data, err = decodeFlate(data)
+			if err == nil {
+				data, err = doc.applyPredictor(data, filterParams)
+			}
+		case name("ASCIIHexDecode"), name("AHx"):
+			data, err = decodeASCIIHex(data)
+		case name("ASCII85Decode"), name("A85"):
+			data, err = decodeASCII85(data)
+		default:
+			return nil, fmt.Errorf("unsupported stream filter %v", filter)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return data, nil
+}
+
+// decodeFlate tolerates truncated streams and streams without the zlib header,
+// both are common in PDFs produced by web tools.
+func decodeFlate(data []byte) ([]byte, error) {
+	var reader io.ReadCloser
+
+	reader, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		reader = flate.NewReader(bytes.NewReader(data))
+	}
+
+	defer reader.Close()
+
+	decoded, err := io.ReadAll(io.LimitReader(reader, maxDecodedSize+1))
+	if len(decoded) > maxDecodedSize {
+		return nil, ErrStreamTooLarge
+	}
+
+	if err != nil && len(decoded) == 0 {
+		return nil, err
+	}
+
+	return decoded, nil
+}
+
+func decodeASCIIHex(data []byte) ([]byte, error) {
+	var digits []byte
+
+	for _, c := range data {
+		if c == '>' {
+			break
+		}
+
+		if !isWhitespace(c) {
+			digits = append(digits, c)
+		}
+	}
+
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+
+	decoded := make([]byte, len(digits)/2)
+
+	_, err := hex.Decode(decoded, digits)
+
+	return decoded, err
+}
+
+func decodeASCII85(data []byte) ([]byte, error) {
+	data = bytes.TrimPrefix(bytes.TrimSpace(data), []byte("<~"))
+
+	if end := bytes.Index(data, []byte("~>")); end >= 0 {
+		data = data[:end]
+	}
+
+	decoded := make([]byte, 4*len(data)/5+4)
+
+	written, _, err := ascii85.Decode(decoded, data, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return decoded[:written], nil
+}
+
+// applyPredictor reverses the PNG predictors used mostly by cross-reference and
+// object streams.
+func (doc *document) applyPredictor(data []byte, params dict) ([]byte, error) {
+	if params == nil {
+		return data, nil
+	}
+
+	predictor := int(doc.number(params["Predictor"]))
+	if predictor < 10 {
+		return data, nil
+	}
+
+	columns := int(doc.number(params["Columns"]))
+	if columns <= 0 {
+		columns = 1
+	}
+
+	colors := int(doc.number(params["Colors"]))
+	if colors <= 0 {
+		colors = 1
+	}
+
+	bitsPerComponent := int(doc.number(params["BitsPerComponent"]))
+	if bitsPerComponent <= 0 {
+		bitsPerComponent = 8
+	}
+
+	bytesPerPixel := max(1, colors*bitsPerComponent/8)
+	rowLength := (columns*colors*bitsPerComponent + 7) / 8
+
+	var decoded []byte
+	previous := make([]byte, rowLength)
+
+	for pos := 0; pos+rowLength < len(data)+1 && pos < len(data); pos += rowLength + 1 {
+		filterType := data[pos]
+		row := make([]byte, rowLength)
+		copy(row, data[pos+1:min(pos+1+rowLength, len(data))])
+
+		for i := range row {
+			var left, up, upLeft byte
+
+			if i >= bytesPerPixel {
+				left = row[i-bytesPerPixel]
+				upLeft = previous[i-bytesPerPixel]
+			}
+
+			up = previous[i]
+
+			switch filterType {
+			case 1:
+				row[i] += left
+			case 2:
+				row[i] += up
+			case 3:
+				row[i] += byte((int(left) + int(up)) / 2)
+			case 4:
+				row[i] += paeth(left, up, upLeft)
+			}
+		}
+
+		decoded = append(decoded, row...)
+		previous = row
+	}
+
+	return decoded, nil
+}
+
+func paeth(a, b, c byte) byte {
+	p := int(a) + int(b) - int(c)
+	pa, pb, pc := abs(p-int(a)), abs(p-int(b)), abs(p-int(c))
+
+	if pa <= pb && pa <= pc {
+		return a
+	}
+
+	if pb <= pc {
+		return b
+	}
+
+	return c
+}
+
+func abs(value int) int {
+	if value < 0 {
+		return -value
+	}
+
+	return value
+}
@@ -0,0 +1,37 @@
+package pdftext
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"testing"
+)
+
+func compress(t *testing.T, size int) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	writer := zlib.NewWriter(&buf)
+
+	if _, err := writer.Write(make([]byte, size)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestDecodeFlateLimit(t *testing.T) {
+	decoded, err := decodeFlate(compress(t, maxDecodedSize))
+	if err != nil || len(decoded) != maxDecodedSize {
+		t.Errorf("stream at the limit decoded to %d bytes, %v", len(decoded), err)
+	}
+
+	if _, err = decodeFlate(compress(t, maxDecodedSize+1)); !errors.Is(err, ErrStreamTooLarge) {
+		t.Errorf("expected ErrStreamTooLarge, got %v", err)
+	}
+}
@@ -0,0 +1,364 @@
+package pdftext
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// font maps character codes of a string operand to text and glyph widths.
+type font struct {
+	composite bool
+	toUnicode map[uint32]string
+	encoding  [256]rune
+	widths    map[uint32]float64
+	// defaultWidth and widths are in glyph space units divided by 1000,
+	// except for Type3 fonts which are scaled by their font matrix.
+	defaultWidth float64
+	widthScale   float64
+}
+
+type glyph struct {
+	text  string
+	width float64
+	space bool
+}
+
+func (doc *document) loadFont(fontDict dict) *font {
+	f := &font{
+		widths:       make(map[uint32]float64),
+		defaultWidth: 500,
+		widthScale:   0.001,
+	}
+
+	f.encoding = standardEncoding()
+
+	if fontDict == nil {
+		return f
+	}
+
+	if toUnicode, ok := doc.resolve(fontDict["ToUnicode"]).(stream); ok {
+		if data, err := doc.decodeStream(toUnicode); err == nil {
+			f.toUnicode = parseCMap(data)
+		}
+	}
+
+	if fontDict["Subtype"] == name("Type0") {
+		f.composite = true
+		f.loadCompositeWidths(doc, fontDict)
+
+		return f
+	}
+
+	if fontDict["Subtype"] == name("Type3") {
+		if matrix := doc.array(fontDict["FontMatrix"]); len(matrix) == 6 {
+			f.widthScale = doc.number(matrix[0])
+		}
+
+		f.defaultWidth = 0
+	}
+
+	f.loadEncoding(doc, fontDict)
+	f.loadSimpleWidths(doc, fontDict)
+
+	return f
+}
+
+func (f *font) loadEncoding(doc *document, fontDict dict) {
+	encoding := doc.resolve(fontDict["Encoding"])
+
+	baseEncoding := encoding
+	if encodingDict := doc.dict(encoding); encodingDict != nil {
+		baseEncoding = encodingDict["BaseEncoding"]
+	}
+
+	switch baseEncoding {
+	case name("WinAnsiEncoding"):
+		f.encoding = charmapEncoding(charmap.Windows1252)
+	case name("MacRomanEncoding"):
+		f.encoding = charmapEncoding(charmap.Macintosh)
+	}
+
+	encodingDict := doc.dict(encoding)
+	if encodingDict == nil {
+		return
+	}
+
+	code := 0
+
+	for _, item := range doc.array(encodingDict["Differences"]) {
+		switch item := doc.resolve(item).(type) {
+		case int64:
+			code = int(item)
+		case name:
+			if code >= 0 && code < 256 {
+				if r, ok := glyphNameToRune(string(item)); ok {
+					f.encoding[code] = r
+				}
+			}
+
+			code++
+		}
+	}
+}
+
+func (f *font) loadSimpleWidths(doc *document, fontDict dict) {
+	if descriptor := doc.dict(fontDict["FontDescriptor"]); descriptor != nil && descriptor["MissingWidth"] != nil {
+		f.defaultWidth = doc.number(descriptor["MissingWidth"])
+	}
+
+	firstChar := int(doc.number(fontDict["FirstChar"]))
+
+	for index, width := range doc.array(fontDict["Widths"]) {
+		f.widths[uint32(firstChar+index)] = doc.number(width)
+	}
+}
+
+func (f *font) loadCompositeWidths(doc *document, fontDict dict) {
+	descendants := doc.array(fontDict["DescendantFonts"])
+	if len(descendants) == 0 {
+		return
+	}
+
+	descendant := doc.dict(descendants[0])
+	if descendant == nil {
+		return
+	}
+
+	f.defaultWidth = 1000
+	if descendant["DW"] != nil {
+		f.defaultWidth = doc.number(descendant["DW"])
+	}
+
+	widths := doc.array(descendant["W"])
+
+	for i := 0; i < len(widths); {
+		first := uint32(doc.number(widths[i]))
+
+		if i+1 < len(widths) {
+			if list, ok := doc.resolve(widths[i+1]).(array); ok {
+				for index, width := range list {
+					f.widths[first+uint32(index)] = doc.number(width)
+				}
+
+				i += 2
+
+				continue
+			}
+		}
+
+		if i+2 >= len(widths) {
+			return
+		}
+
+		last := uint32(doc.number(widths[i+1]))
+		width := doc.number(widths[i+2])
+
+		for code := first; code <= last && code-first < 65536; code++ {
+			f.widths[code] = width
+		}
+
+		i += 3
+	}
+}
+
+// decode splits a string operand into glyphs. Composite fonts are assumed to use
+// two-byte codes (Identity-H), which is what every common producer emits.
+func (f *font) decode(data []byte) []glyph {
+	var glyphs []glyph
+
+	step := 1
+	if f.composite {
+		step = 2
+	}
+
+	for i := 0; i+step <= len(data); i += step {
+		code := uint32(data[i])
+		if step == 2 {
+			code = code<<8 | uint32(data[i+1])
+		}
+
+		text, ok := f.toUnicode[code]
+		if !ok && !f.composite {
+			if r := f.encoding[code]; r != 0 {
+				text = string(r)
+			}
+		}
+
+		width, ok := f.widths[code]
+		if !ok {
+			width = f.defaultWidth
+		}
+
+		glyphs = append(glyphs, glyph{
+			text:  text,
+			width: width * f.widthScale,
+			space: step == 1 && code == ' ',
+		})
+	}
+
+	return glyphs
+}
+
+func charmapEncoding(cm *charmap.Charmap) [256]rune {
+	var encoding [256]rune
+
+	for code := 32; code < 256; code++ {
+		if r := cm.DecodeByte(byte(code)); r != '�' {
+			encoding[code] = r
+		}
+	}
+
+	return encoding
+}
+
+// standardEncoding approximates the Adobe standard encoding with ASCII, the
+// differences only affect rarely used punctuation.
+func standardEncoding() [256]rune {
+	var encoding [256]rune
+
+	for code := 32; code < 127; code++ {
+		encoding[code] = rune(code)
+	}
+
+	encoding['\''] = '’'
+	encoding['`'] = '‘'
+
+	return encoding
+}
+
+// parseCMap reads the bfchar and bfrange mappings of a ToUnicode CMap.
+func parseCMap(data []byte) map[uint32]string {
+	mapping := make(map[uint32]string)
+
+	l := &lexer{data: data}
+
+	var operands []any
+
+	for {
+		token, err := l.next()
+		if err != nil {
+			return mapping
+		}
+
+		if d, ok := token.(delimiter); ok && d == "[" {
+			items, err := l.readArray()
+			if err != nil {
+				return mapping
+			}
+
+			operands = append(operands, items)
+
+			continue
+		}
+
+		kw, ok := token.(keyword)
+		if !ok {
+			operands = append(operands, token)
+			continue
+		}
+
+		switch kw {
+		case "endbfchar":
+			for i := 0; i+1 < len(operands); i += 2 {
+				src, ok := operands[i].([]byte)
+				dst, ok2 := operands[i+1].([]byte)
+
+				if ok && ok2 {
+					mapping[codeFromBytes(src)] = utf16BEToString(dst)
+				}
+			}
+		case "endbfrange":
+			for i := 0; i+2 < len(operands); i += 3 {
+				low, ok := operands[i].([]byte)
+				high, ok2 := operands[i+1].([]byte)
+
+				if !ok || !ok2 {
+					continue
+				}
+
+				addCMapRange(mapping, codeFromBytes(low), codeFromBytes(high), operands[i+2])
+			}
+		}
+
+		operands = operands[:0]
+	}
+}
+
+func addCMapRange(mapping map[uint32]string, low, high uint32, destination any) {
+	if high < low || high-low > 65535 {
+		return
+	}
+
+	switch destination := destination.(type) {
+	case []byte:
+		runes := []rune(utf16BEToString(destination))
+		if len(runes) == 0 {
+			return
+		}
+
+		for code := low; code <= high; code++ {
+			shifted := append([]rune{}, runes...)
+			shifted[len(shifted)-1] += rune(code - low)
+			mapping[code] = string(shifted)
+		}
+	case array:
+		for index, item := range destination {
+			if dst, ok := item.([]byte); ok && low+uint32(index) <= high {
+				mapping[low+uint32(index)] = utf16BEToString(dst)
+			}
+		}
+	}
+}
+
+func codeFromBytes(data []byte) uint32 {
+	var code uint32
+
+	for _, b := range data {
+		code = code<<8 | uint32(b)
+	}
+
+	return code
+}
+
+func utf16BEToString(data []byte) string {
+	units := make([]uint16, 0, len(data)/2)
+
+	for i := 0; i+1 < len(data); i += 2 {
+		units = append(units, uint16(data[i])<<8|uint16(data[i+1]))
+	}
+
+	return string(utf16.Decode(units))
+}
+
+// glyphNameToRune resolves glyph names used in "Differences" arrays.
+func glyphNameToRune(glyphName string) (rune, bool) {
+	if r, ok := glyphNames[glyphName]; ok {
+		return r, true
+	}
+
+	if len(glyphName) == 1 {
+		return rune(glyphName[0]), true
+	}
+
+	if hexCode, ok := strings.CutPrefix(glyphName, "uni"); ok && len(hexCode) == 4 {
+		if code, err := strconv.ParseUint(hexCode, 16, 32); err == nil {
+			return rune(code), true
+		}
+	}
+
+	if hexCode, ok := strings.CutPrefix(glyphName, "u"); ok && len(hexCode) >= 4 && len(hexCode) <= 6 {
+		if code, err := strconv.ParseUint(hexCode, 16, 32); err == nil {
+			return rune(code), true
+		}
+	}
+
+	// Glyph names with suffixes, e.g. "a.sc", fall back to their base glyph.
+	if base, _, found := strings.Cut(glyphName, "."); found && base != "" {
+		return glyphNameToRune(base)
+	}
+
+	return 0, false
+}
@@ -0,0 +1,179 @@
+package pdftext
+
+// glyphNames maps the Adobe glyph names needed for Slovak and Czech menus to runes.
+var glyphNames = map[string]rune{
+	"Aacute":         'Á',
+	"Acaron":         'Ǎ',
+	"Acircumflex":    'Â',
+	"Adieresis":      'Ä',
+	"Agrave":         'À',
+	"Aring":          'Å',
+	"Cacute":         'Ć',
+	"Ccaron":         'Č',
+	"Ccircumflex":    'Ĉ',
+	"Dcaron":         'Ď',
+	"Eacute":         'É',
+	"Ecaron":         'Ě',
+	"Ecircumflex":    'Ê',
+	"Edieresis":      'Ë',
+	"Egrave":         'È',
+	"Euro":           '€',
+	"Iacute":         'Í',
+	"Icaron":         'Ǐ',
+	"Icircumflex":    'Î',
+	"Idieresis":      'Ï',
+	"Igrave":         'Ì',
+	"Lacute":         'Ĺ',
+	"Lcaron":         'Ľ',
+	"Nacute":         'Ń',
+	"Ncaron":         'Ň',
+	"Ngrave":         'Ǹ',
+	"Oacute":         'Ó',
+	"Ocaron":         'Ǒ',
+	"Ocircumflex":    'Ô',
+	"Odieresis":      'Ö',
+	"Ograve":         'Ò',
+	"Racute":         'Ŕ',
+	"Rcaron":         'Ř',
+	"Sacute":         'Ś',
+	"Scaron":         'Š',
+	"Scircumflex":    'Ŝ',
+	"Tcaron":         'Ť',
+	"Uacute":         'Ú',
+	"Ucaron":         'Ǔ',
+	"Ucircumflex":    'Û',
+	"Udieresis":      'Ü',
+	"Ugrave":         'Ù',
+	"Uring":          'Ů',
+	"Yacute":         'Ý',
+	"Ycircumflex":    'Ŷ',
+	"Ydieresis":      'Ÿ',
+	"Ygrave":         'Ỳ',
+	"Zacute":         'Ź',
+	"Zcaron":         'Ž',
+	"Zcircumflex":    'Ẑ',
+	"aacute":         'á',
+	"acaron":         'ǎ',
+	"acircumflex":    'â',
+	"adieresis":      'ä',
+	"agrave":         'à',
+	"ampersand":      '&',
+	"aring":          'å',
+	"asciicircum":    '^',
+	"asciitilde":     '~',
+	"asterisk":       '*',
+	"at":             '@',
+	"backslash":      '\\',
+	"bar":            '|',
+	"braceleft":      '{',
+	"braceright":     '}',
+	"bracketleft":    '[',
+	"bracketright":   ']',
+	"bullet":         '•',
+	"cacute":         'ć',
+	"ccaron":         'č',
+	"ccircumflex":    'ĉ',
+	"colon":          ':',
+	"comma":          ',',
+	"copyright":      '©',
+	"dagger":         '†',
+	"dcaron":         'ď',
+	"degree":         '°',
+	"divide":         '÷',
+	"dollar":         '$',
+	"eacute":         'é',
+	"ecaron":         'ě',
+	"ecircumflex":    'ê',
+	"edieresis":      'ë',
+	"egrave":         'è',
+	"eight":          '8',
+	"ellipsis":       '…',
+	"emdash":         '—',
+	"endash":         '–',
+	"equal":          '=',
+	"exclam":         '!',
+	"fi":             'ﬁ',
+	"five":           '5',
+	"fl":             'ﬂ',
+	"four":           '4',
+	"germandbls":     'ß',
+	"grave":          '`',
+	"greater":        '>',
+	"guillemotleft":  '«',
+	"guillemotright": '»',
+	"hyphen":         '-',
+	"iacute":         'í',
+	"icaron":         'ǐ',
+	"icircumflex":    'î',
+	"idieresis":      'ï',
+	"igrave":         'ì',
+	"lacute":         'ĺ',
+	"lcaron":         'ľ',
+	"less":           '<',
+	"middot":         '·',
+	"minus":          '−',
+	"multiply":       '×',
+	"nacute":         'ń',
+	"nbspace":        '\u00a0',
+	"ncaron":         'ň',
+	"ngrave":         'ǹ',
+	"nine":           '9',
+	"numbersign":     '#',
+	"oacute":         'ó',
+	"ocaron":         'ǒ',
+	"ocircumflex":    'ô',
+	"odieresis":      'ö',
+	"ograve":         'ò',
+	"one":            '1',
+	"onehalf":        '½',
+	"onequarter":     '¼',
+	"parenleft":      '(',
+	"parenright":     ')',
+	"percent":        '%',
+	"period":         '.',
+	"periodcentered": '·',
+	"plus":           '+',
+	"question":       '?',
+	"quotedbl":       '"',
+	"quotedblbase":   '„',
+	"quotedblleft":   '“',
+	"quotedblright":  '”',
+	"quoteleft":      '‘',
+	"quoteright":     '’',
+	"quotesinglbase": '‚',
+	"quotesingle":    '\'',
+	"racute":         'ŕ',
+	"rcaron":         'ř',
+	"registered":     '®',
+	"sacute":         'ś',
+	"scaron":         'š',
+	"scircumflex":    'ŝ',
+	"section":        '§',
+	"semicolon":      ';',
+	"seven":          '7',
+	"six":            '6',
+	"slash":          '/',
+	"space":          ' ',
+	"tcaron":         'ť',
+	"tdieresis":      'ẗ',
+	"three":          '3',
+	"threequarters":  '¾',
+	"trademark":      '™',
+	"two":            '2',
+	"uacute":         'ú',
+	"ucaron":         'ǔ',
+	"ucircumflex":    'û',
+	"udieresis":      'ü',
+	"ugrave":         'ù',
+	"underscore":     '_',
+	"uring":          'ů',
+	"yacute":         'ý',
+	"ycircumflex":    'ŷ',
+	"ydieresis":      'ÿ',
+	"ygrave":         'ỳ',
+	"yring":          'ẙ',
+	"zacute":         'ź',
+	"zcaron":         'ž',
+	"zcircumflex":    'ẑ',
+	"zero":           '0',
+}
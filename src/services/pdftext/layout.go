@@ -0,0 +1,126 @@
+package pdftext
+
+import (
+	"math"
+	"menucko/services/layout"
+	"sort"
+	"strings"
+)
+
+// Row is a line of text split into cells at wide horizontal gaps, e.g. a dish
+// and its price in a right-hand column.
+type Row struct {
+	Page     int
+	Y        float64
+	FontSize float64
+	Cells    []Cell
+}
+
+type Cell struct {
+	X     float64
+	Width float64
+	Text  string
+}
+
+func (row Row) Text() string {
+	texts := make([]string, len(row.Cells))
+
+	for index, cell := range row.Cells {
+		texts[index] = cell.Text
+	}
+
+	return strings.Join(texts, " ")
+}
+
+// ReadingOrder groups runs into lines like Lines, but reads multi-column pages
+// column by column, see layout.ReadingOrder.
+func ReadingOrder(runs []Run) []Line {
+	var lines []Line
+
+	for _, pageRuns := range splitPages(runs) {
+		pageLines := Lines(pageRuns)
+		lineRuns := make([][]Run, len(pageLines))
+
+		for index, line := range pageLines {
+			lineRuns[index] = line.Runs
+		}
+
+		for _, part := range layout.ReadingOrder(lineRuns, runSpan) {
+			source := pageLines[part.Line]
+
+			lines = append(lines, Line{Page: source.Page, Y: source.Y, Runs: part.Items})
+		}
+	}
+
+	return lines
+}
+
+// Rows returns the lines in reading order with each line split into cells.
+func Rows(runs []Run) []Row {
+	lines := ReadingOrder(runs)
+	rows := make([]Row, 0, len(lines))
+
+	for _, line := range lines {
+		row := Row{
+			Page: line.Page,
+			Y:    line.Y,
+		}
+
+		for _, cellRuns := range layout.Cells(line.Runs, runSpan) {
+			for _, run := range cellRuns {
+				row.FontSize = math.Max(row.FontSize, run.FontSize)
+			}
+
+			row.Cells = append(row.Cells, newCell(cellRuns))
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows
+}
+
+func runSpan(run Run) layout.Span {
+	return layout.Span{Start: run.X, End: run.X + run.Width, Size: run.FontSize}
+}
+
+func newCell(runs []Run) Cell {
+	return Cell{
+		X:     runs[0].X,
+		Width: runsEnd(runs) - runs[0].X,
+		Text:  Line{Runs: runs}.Text(),
+	}
+}
+
+func runsEnd(runs []Run) float64 {
+	end := math.Inf(-1)
+
+	for _, run := range runs {
+		end = math.Max(end, run.X+run.Width)
+	}
+
+	return end
+}
+
+func splitPages(runs []Run) [][]Run {
+	var pages [][]Run
+
+	indexes := make(map[int]int)
+
+	for _, run := range runs {
+		index, ok := indexes[run.Page]
+		if !ok {
+			index = len(pages)
+			indexes[run.Page] = index
+			pages = append(pages, nil)
+		}
+
+		pages[index] = append(pages[index], run)
+	}
+
+	sort.SliceStable(pages, func(i, j int) bool {
+		return pages[i][0].Page < pages[j][0].Page
+	})
+
+	return pages
+}
@@ -0,0 +1,98 @@
+package pdftext
+
+import (
+	"testing"
+)
+
+// textRun approximates the width of the text with half of the font size per character.
+func textRun(x, y float64, text string) Run {
+	return Run{Page: 1, X: x, Y: y, Width: 6 * float64(len([]rune(text))), FontSize: 12, Text: text}
+}
+
+func TestReadingOrderColumns(t *testing.T) {
+	runs := []Run{
+		textRun(60, 800, "Týždenné menu reštaurácie od pondelka do piatku"),
+		textRun(60, 760, "Pondelok"),
+		textRun(320, 760, "Streda"),
+		textRun(60, 745, "Bravčový guláš, chlieb"),
+		textRun(320, 745, "Hubové rizoto so syrom"),
+		textRun(60, 730, "Utorok"),
+		textRun(320, 730, "Štvrtok"),
+		textRun(60, 715, "Vyprážaný rezeň, šalát"),
+		textRun(320, 715, "Losos, pečené zemiaky"),
+		textRun(60, 680, "Ku každému menu polievka a dezert v cene menu"),
+	}
+
+	text := LinesText(ReadingOrder(runs))
+
+	expected := "Týždenné menu reštaurácie od pondelka do piatku\n" +
+		"Pondelok\nBravčový guláš, chlieb\nUtorok\nVyprážaný rezeň, šalát\n" +
+		"Streda\nHubové rizoto so syrom\nŠtvrtok\nLosos, pečené zemiaky\n" +
+		"Ku každému menu polievka a dezert v cene menu"
+
+	if text != expected {
+		t.Errorf("unexpected text %q", text)
+	}
+}
+
+func TestRowsTable(t *testing.T) {
+	runs := []Run{
+		textRun(60, 760, "M1: Kurací rezeň v cestíčku"),
+		textRun(480, 760, "7,50 €"),
+		textRun(60, 745, "zemiaková kaša"),
+		textRun(60, 715, "M2: Bravčové výpečky"),
+		textRun(480, 715, "7,90 €"),
+	}
+
+	rows := Rows(runs)
+	if len(rows) != 3 {
+		t.Fatalf("expected 3 rows, got %+v", rows)
+	}
+
+	if len(rows[0].Cells) != 2 || rows[0].Cells[0].Text != "M1: Kurací rezeň v cestíčku" || rows[0].Cells[1].Text != "7,50 €" {
+		t.Errorf("unexpected first row %+v", rows[0])
+	}
+
+	if rows[0].Cells[1].X != 480 || rows[0].Y != 760 {
+		t.Errorf("unexpected first row position %+v", rows[0])
+	}
+
+	if len(rows[1].Cells) != 1 || rows[1].Text() != "zemiaková kaša" {
+		t.Errorf("unexpected second row %+v", rows[1])
+	}
+
+	if rows[2].Text() != "M2: Bravčové výpečky 7,90 €" {
+		t.Errorf("unexpected third row %+v", rows[2])
+	}
+}
+
+func TestRowsWithinColumns(t *testing.T) {
+	runs := []Run{
+		textRun(40, 760, "Polievky"),
+		textRun(300, 760, "Hlavné jedlá"),
+		textRun(40, 745, "Paradajková"),
+		textRun(200, 745, "1,50 €"),
+		textRun(300, 745, "Pečené kuracie stehno"),
+		textRun(500, 745, "7,20 €"),
+	}
+
+	rows := Rows(runs)
+
+	var texts []string
+	for _, row := range rows {
+		texts = append(texts, row.Text())
+	}
+
+	expected := []string{"Polievky", "Paradajková 1,50 €", "Hlavné jedlá", "Pečené kuracie stehno 7,20 €"}
+
+	if len(texts) != len(expected) {
+		t.Fatalf("unexpected rows %q", texts)
+	}
+
+	for index := range expected {
+		if texts[index] != expected[index] {
+			t.Errorf("unexpected rows %q", texts)
+			break
+		}
+	}
+}
@@ -0,0 +1,378 @@
+package pdftext
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+type name string
+type keyword string
+type delimiter string
+type dict map[name]any
+type array []any
+
+type ref struct {
+	num int
+	gen int
+}
+
+type stream struct {
+	dict dict
+	data []byte
+}
+
+var errEOF = errors.New("unexpected end of data")
+
+type lexer struct {
+	data []byte
+	pos  int
+}
+
+func isWhitespace(c byte) bool {
+	return c == 0 || c == '\t' || c == '\n' || c == '\f' || c == '\r' || c == ' '
+}
+
+func isDelimiter(c byte) bool {
+	return bytes.IndexByte([]byte("()<>[]{}/%"), c) >= 0
+}
+
+func (l *lexer) skipWhitespace() {
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+
+		if c == '%' {
+			for l.pos < len(l.data) && l.data[l.pos] != '\n' && l.data[l.pos] != '\r' {
+				l.pos++
+			}
+
+			continue
+		}
+
+		if !isWhitespace(c) {
+			return
+		}
+
+		l.pos++
+	}
+}
+
+// next returns the next token: an int64, float64, name, []byte string, keyword or delimiter.
+func (l *lexer) next() (any, error) {
+	l.skipWhitespace()
+
+	if l.pos >= len(l.data) {
+		return nil, errEOF
+	}
+
+	c := l.data[l.pos]
+
+	switch {
+	case c == '/':
+		return l.readName(), nil
+	case c == '(':
+		return l.readLiteralString()
+	case c == '<':
+		if l.pos+1 < len(l.data) && l.data[l.pos+1] == '<' {
+			l.pos += 2
+			return delimiter("<<"), nil
+		}
+
+		return l.readHexString()
+	case c == '>':
+		if l.pos+1 < len(l.data) && l.data[l.pos+1] == '>' {
+			l.pos += 2
+			return delimiter(">>"), nil
+		}
+
+		l.pos++
+		return nil, fmt.Errorf("unexpected \">\" at offset %d", l.pos-1)
+	case c == '[' || c == ']' || c == '{' || c == '}':
+		l.pos++
+		return delimiter([]byte{c}), nil
+	case c == '+' || c == '-' || c == '.' || (c >= '0' && c <= '9'):
+		return l.readNumber(), nil
+	}
+
+	start := l.pos
+	for l.pos < len(l.data) && !isWhitespace(l.data[l.pos]) && !isDelimiter(l.data[l.pos]) {
+		l.pos++
+	}
+
+	if start == l.pos {
+		l.pos++
+		return nil, fmt.Errorf("unexpected byte 0x%02x at offset %d", c, start)
+	}
+
+	return keyword(l.data[start:l.pos]), nil
+}
+
+func (l *lexer) readName() name {
+	l.pos++
+
+	var buf bytes.Buffer
+
+	for l.pos < len(l.data) && !isWhitespace(l.data[l.pos]) && !isDelimiter(l.data[l.pos]) {
+		c := l.data[l.pos]
+
+		if c == '#' && l.pos+2 < len(l.data) {
+			if value, err := strconv.ParseUint(string(l.data[l.pos+1:l.pos+3]), 16, 8); err == nil {
+				buf.WriteByte(byte(value))
+				l.pos += 3
+				continue
+			}
+		}
+
+		buf.WriteByte(c)
+		l.pos++
+	}
+
+	return name(buf.String())
+}
+
+func (l *lexer) readNumber() any {
+	start := l.pos
+	isReal := false
+
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+
+		if c == '.' {
+			isReal = true
+		} else if !(c >= '0' && c <= '9') && !((c == '+' || c == '-') && l.pos == start) {
+			break
+		}
+
+		l.pos++
+	}
+
+	text := string(l.data[start:l.pos])
+
+	if !isReal {
+		if value, err := strconv.ParseInt(text, 10, 64); err == nil {
+			return value
+		}
+	}
+
+	value, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return float64(0)
+	}
+
+	return value
+}
+
+func (l *lexer) readLiteralString() ([]byte, error) {
+	l.pos++
+
+	var buf bytes.Buffer
+	depth := 1
+
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return buf.Bytes(), nil
+			}
+		case '\\':
+			if l.pos >= len(l.data) {
+				return nil, errEOF
+			}
+
+			c = l.data[l.pos]
+			l.pos++
+
+			switch c {
+			case 'n':
+				buf.WriteByte('\n')
+			case 'r':
+				buf.WriteByte('\r')
+			case 't':
+				buf.WriteByte('\t')
+			case 'b':
+				buf.WriteByte('\b')
+			case 'f':
+				buf.WriteByte('\f')
+			case '\r':
+				if l.pos < len(l.data) && l.data[l.pos] == '\n' {
+					l.pos++
+				}
+			case '\n':
+			default:
+				if c >= '0' && c <= '7' {
+					value := int(c - '0')
+
+					for i := 0; i < 2 && l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '7'; i++ {
+						value = value*8 + int(l.data[l.pos]-'0')
+						l.pos++
+					}
+
+					buf.WriteByte(byte(value))
+					continue
+				}
+
+				buf.WriteByte(c)
+			}
+
+			continue
+		}
+
+		buf.WriteByte(c)
+	}
+
+	return nil, errEOF
+}
+
+func (l *lexer) readHexString() ([]byte, error) {
+	l.pos++
+
+	var buf bytes.Buffer
+	var digits []byte
+
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+
+		if c == '>' {
+			if len(digits) == 1 {
+				digits = append(digits, '0')
+			}
+
+			if len(digits) == 2 {
+				value, _ := strconv.ParseUint(string(digits), 16, 8)
+				buf.WriteByte(byte(value))
+			}
+
+			return buf.Bytes(), nil
+		}
+
+		if isWhitespace(c) {
+			continue
+		}
+
+		digits = append(digits, c)
+
+		if len(digits) == 2 {
+			value, err := strconv.ParseUint(string(digits), 16, 8)
+			if err != nil {
+				return nil, fmt.Errorf("invalid hex string at offset %d", l.pos)
+			}
+
+			buf.WriteByte(byte(value))
+			digits = digits[:0]
+		}
+	}
+
+	return nil, errEOF
+}
+
+// readObject reads a complete object, i.e. arrays and dictionaries are read with all
+// their items and "num gen R" is returned as a reference.
+func (l *lexer) readObject() (any, error) {
+	token, err := l.next()
+	if err != nil {
+		return nil, err
+	}
+
+	return l.completeObject(token)
+}
+
+func (l *lexer) completeObject(token any) (any, error) {
+	switch token := token.(type) {
+	case delimiter:
+		switch token {
+		case "[":
+			return l.readArray()
+		case "<<":
+			return l.readDict()
+		}
+
+		return token, nil
+
+	case int64:
+		pos := l.pos
+
+		gen, err := l.next()
+		if genNum, ok := gen.(int64); err == nil && ok {
+			if r, err := l.next(); err == nil && r == keyword("R") {
+				return ref{num: int(token), gen: int(genNum)}, nil
+			}
+		}
+
+		l.pos = pos
+		return token, nil
+
+	case keyword:
+		switch token {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null":
+			return nil, nil
+		}
+	}
+
+	return token, nil
+}
+
+func (l *lexer) readArray() (array, error) {
+	var items array
+
+	for {
+		token, err := l.next()
+		if err != nil {
+			return nil, err
+		}
+
+		if token == delimiter("]") {
+			return items, nil
+		}
+
+		item, err := l.completeObject(token)
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, item)
+	}
+}
+
+func (l *lexer) readDict() (dict, error) {
+	items := dict{}
+
+	for {
+		token, err := l.next()
+		if err != nil {
+			return nil, err
+		}
+
+		if token == delimiter(">>") {
+			return items, nil
+		}
+
+		key, ok := token.(name)
+		if !ok {
+			// Skip malformed entries instead of failing the whole document.
+			continue
+		}
+
+		value, err := l.readObject()
+		if err != nil {
+			return nil, err
+		}
+
+		if value == delimiter(">>") {
+			return items, nil
+		}
+
+		items[key] = value
+	}
+}
@@ -0,0 +1,168 @@
+package pdftext
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+)
+
+type PDFText interface {
+	ExtractText(ctx context.Context, pdfBytes []byte) (string, error)
+	ExtractRuns(ctx context.Context, pdfBytes []byte) ([]Run, error)
+}
+
+// Run is a piece of text drawn at one position. Coordinates are in PDF user space,
+// so Y grows towards the top of the page.
+type Run struct {
+	Page     int
+	X        float64
+	Y        float64
+	Width    float64
+	FontSize float64
+	Text     string
+}
+
+type DevPDFText struct {
+	Text string
+	Runs []Run
+}
+
+func (pdfText DevPDFText) ExtractText(ctx context.Context, pdfBytes []byte) (string, error) {
+	if pdfText.Text == "" && len(pdfText.Runs) > 0 {
+		return LinesText(ReadingOrder(pdfText.Runs)), nil
+	}
+
+	return pdfText.Text, nil
+}
+
+func (pdfText DevPDFText) ExtractRuns(ctx context.Context, pdfBytes []byte) ([]Run, error) {
+	return pdfText.Runs, nil
+}
+
+type ProdPDFText struct{}
+
+func (pdfText ProdPDFText) ExtractText(ctx context.Context, pdfBytes []byte) (string, error) {
+	runs, err := pdfText.ExtractRuns(ctx, pdfBytes)
+	if err != nil {
+		return "", err
+	}
+
+	return LinesText(ReadingOrder(runs)), nil
+}
+
+func (ProdPDFText) ExtractRuns(ctx context.Context, pdfBytes []byte) ([]Run, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	doc, err := parseDocument(pdfBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	var runs []Run
+
+	for index, p := range doc.pages() {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		in := newInterpreter(doc, index+1)
+		in.run(doc.pageContent(p), p.resources, 0)
+
+		runs = append(runs, in.runs...)
+	}
+
+	return runs, nil
+}
+
+// Line is a sequence of runs sharing a baseline, ordered from left to right.
+type Line struct {
+	Page int
+	Y    float64
+	Runs []Run
+}
+
+func (line Line) Text() string {
+	var text strings.Builder
+
+	for index, run := range line.Runs {
+		if index > 0 {
+			previous := line.Runs[index-1]
+			gap := run.X - (previous.X + previous.Width)
+
+			if gap > 0.2*math.Max(run.FontSize, previous.FontSize) &&
+				!strings.HasSuffix(previous.Text, " ") && !strings.HasPrefix(run.Text, " ") {
+				text.WriteString(" ")
+			}
+		}
+
+		text.WriteString(run.Text)
+	}
+
+	return strings.TrimSpace(text.String())
+}
+
+// Lines groups runs into lines in reading order: pages first, then top to bottom.
+// Runs belong to the same line when their baselines differ by less than
+// 40 % of the font size, which tolerates superscripts and sloppy generators.
+func Lines(runs []Run) []Line {
+	sorted := make([]Run, len(runs))
+	copy(sorted, runs)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Page != sorted[j].Page {
+			return sorted[i].Page < sorted[j].Page
+		}
+
+		return sorted[i].Y > sorted[j].Y
+	})
+
+	var lines []Line
+
+	for _, run := range sorted {
+		if strings.TrimSpace(run.Text) == "" {
+			continue
+		}
+
+		if len(lines) > 0 {
+			last := &lines[len(lines)-1]
+
+			if last.Page == run.Page && math.Abs(last.Y-run.Y) < 0.4*math.Max(run.FontSize, 1) {
+				last.Runs = append(last.Runs, run)
+				continue
+			}
+		}
+
+		lines = append(lines, Line{Page: run.Page, Y: run.Y, Runs: []Run{run}})
+	}
+
+	for _, line := range lines {
+		sort.SliceStable(line.Runs, func(i, j int) bool {
+			return line.Runs[i].X < line.Runs[j].X
+		})
+	}
+
+	return lines
+}
+
+// LinesText joins the lines with newlines and separates pages with a form feed,
+// like pdftotext does.
+func LinesText(lines []Line) string {
+	var text strings.Builder
+
+	for index, line := range lines {
+		if index > 0 {
+			if lines[index-1].Page != line.Page {
+				text.WriteString("\f")
+			}
+
+			text.WriteString("\n")
+		}
+
+		text.WriteString(line.Text())
+	}
+
+	return text.String()
+}
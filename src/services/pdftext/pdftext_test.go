@@ -0,0 +1,152 @@
+package pdftext
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+)
+
+func readTestPDF(t *testing.T, fileName string) []byte {
+	t.Helper()
+
+	data, err := os.ReadFile("testdata/" + fileName)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	return data
+}
+
+func TestExtractTextType0Font(t *testing.T) {
+	text, err := ProdPDFText{}.ExtractText(context.Background(), readTestPDF(t, "type0.pdf"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "Denné menu 16.04.2024 (utorok)\n" +
+		"Polievka: Hŕstková polievka 0,33l\n" +
+		"M1: Kurací rezeň v cestíčku\n" +
+		"zemiaková kaša, uhorkový šalát\n" +
+		"7.50 €\n" +
+		"M2: Bravčové výpečky\n" +
+		"dusená kapusta, knedľa\n" +
+		"7.90 €\n" +
+		"M3: Cestoviny s tuniakom a olivami\n" +
+		"8.20 €\n" +
+		"Ku každému menu dezert zdarma."
+
+	if text != expected {
+		t.Errorf("unexpected text %q", text)
+	}
+}
+
+func TestExtractTextSimpleFont(t *testing.T) {
+	// The file uses WinAnsi with Differences, TJ kerning, T* and ' operators,
+	// inherited resources and a hex-encoded form XObject.
+	text, err := ProdPDFText{}.ExtractText(context.Background(), readTestPDF(t, "simple.pdf"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if text != "Denné menu Vpravo\nGuláš s knedľou\nCena: 6.90 €" {
+		t.Errorf("unexpected text %q", text)
+	}
+}
+
+func TestExtractRunsPositions(t *testing.T) {
+	runs, err := ProdPDFText{}.ExtractRuns(context.Background(), readTestPDF(t, "simple.pdf"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var formRun *Run
+	for i := range runs {
+		if runs[i].Text == "Vpravo" {
+			formRun = &runs[i]
+		}
+	}
+
+	if formRun == nil {
+		t.Fatalf("form XObject text not found in %+v", runs)
+	}
+
+	if formRun.Page != 1 || formRun.X != 300 || formRun.Y != 700 || formRun.FontSize != 10 {
+		t.Errorf("unexpected run position %+v", *formRun)
+	}
+}
+
+func TestExtractTextErrors(t *testing.T) {
+	if _, err := (ProdPDFText{}).ExtractText(context.Background(), []byte("<html></html>")); !errors.Is(err, ErrInvalidPDF) {
+		t.Errorf("expected ErrInvalidPDF, got %v", err)
+	}
+
+	encrypted := []byte("%PDF-1.4\n1 0 obj\n<< /Type /Catalog >>\nendobj\ntrailer\n<< /Root 1 0 R /Encrypt 2 0 R >>\n")
+	if _, err := (ProdPDFText{}).ExtractText(context.Background(), encrypted); !errors.Is(err, ErrEncrypted) {
+		t.Errorf("expected ErrEncrypted, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := (ProdPDFText{}).ExtractText(ctx, readTestPDF(t, "simple.pdf")); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestExtractTextEncrypted(t *testing.T) {
+	// The files have only an owner password, like menus protected against editing.
+	for _, fileName := range []string{"encrypted-rc4.pdf", "encrypted-aes.pdf", "encrypted-aes256.pdf"} {
+		text, err := ProdPDFText{}.ExtractText(context.Background(), readTestPDF(t, fileName))
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", fileName, err)
+			continue
+		}
+
+		if text != "Denne menu\nGulas 6,90 EUR" {
+			t.Errorf("%s: unexpected text %q", fileName, text)
+		}
+	}
+
+	_, err := ProdPDFText{}.ExtractText(context.Background(), readTestPDF(t, "encrypted-password.pdf"))
+	if !errors.Is(err, ErrEncrypted) {
+		t.Errorf("expected ErrEncrypted for a document with a user password, got %v", err)
+	}
+}
+
+func TestExtractRunsMalformedFontOperand(t *testing.T) {
+	content := "BT (F1) 12 Tf [1] 12 Tf 50 700 Td (Menu) Tj ET"
+	data := []byte("%PDF-1.4\n" +
+		"1 0 obj\n<< /Type /Catalog /Pages 2 0 R >>\nendobj\n" +
+		"2 0 obj\n<< /Type /Pages /Kids [3 0 R] /Count 1 >>\nendobj\n" +
+		"3 0 obj\n<< /Type /Page /Parent 2 0 R /Contents 4 0 R >>\nendobj\n" +
+		"4 0 obj\n<< >>\nstream\n" + content + "\nendstream\nendobj\n" +
+		"trailer\n<< /Root 1 0 R >>\n")
+
+	if _, err := (ProdPDFText{}).ExtractRuns(context.Background(), data); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestLines(t *testing.T) {
+	runs := []Run{
+		{Page: 1, X: 200, Y: 700, Width: 30, FontSize: 10, Text: "6,90 €"},
+		{Page: 1, X: 50, Y: 701, Width: 40, FontSize: 10, Text: "Guláš"},
+		{Page: 1, X: 50, Y: 720, Width: 40, FontSize: 12, Text: "Menu"},
+		{Page: 2, X: 50, Y: 800, Width: 40, FontSize: 10, Text: "Dezert"},
+	}
+
+	text := LinesText(Lines(runs))
+	if text != "Menu\nGuláš 6,90 €\f\nDezert" {
+		t.Errorf("unexpected text %q", text)
+	}
+}
+
+func TestDevPDFText(t *testing.T) {
+	pdfText := DevPDFText{Runs: []Run{{Page: 1, X: 0, Y: 10, Width: 10, FontSize: 10, Text: "Menu"}}}
+
+	text, err := pdfText.ExtractText(context.Background(), nil)
+	if err != nil || text != "Menu" {
+		t.Errorf("unexpected result %q, %v", text, err)
+	}
+}
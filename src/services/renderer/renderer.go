@@ -6,9 +6,9 @@ import (
 	"html/template"
 	"log"
 	"menucko/restaurants"
-	"menucko/services/dateresolver"
+	"menucko/services/clock"
+	"path/filepath"
 	"time"
-	_ "time/tzdata"
 
 	"github.com/tdewolff/minify/v2"
 	"github.com/tdewolff/minify/v2/html"
@@ -17,52 +17,98 @@ import (
 const htmlRendererLogPrefix = "[HTML Renderer]"
 const rendererFatalErrPage = "<!doctype html><html lang=sk><h1>Fatal Error</h1>"
 
+// templateFuncs are the helpers available in the template, "inc" numbers items
+// of a range from one.
+var templateFuncs = template.FuncMap{
+	"inc": func(index int) int {
+		return index + 1
+	},
+}
+
+// Page is one of the rendered pages, the menus of today, of the next working day
+// and the overview of the whole week.
+type Page string
+
+const (
+	PageToday    Page = "today"
+	PageTomorrow Page = "tomorrow"
+	PageWeek     Page = "week"
+)
+
+// Pages are in the order of the navigation.
+var Pages = []Page{PageToday, PageTomorrow, PageWeek}
+
+var pageTitles = map[Page]string{
+	PageToday:    "Dnes",
+	PageTomorrow: "Zajtra",
+	PageWeek:     "Týždeň",
+}
+
 type Renderer interface {
-	RenderMenus(menus *[]restaurants.Menu) (*bytes.Buffer, error)
+	RenderMenus(weeks *[]restaurants.WeeklyMenu, page Page) (*bytes.Buffer, error)
 	GetErrorContent() *bytes.Buffer
 }
 
 type HTMLRenderer struct {
-	DateResolver     dateresolver.DateResolver
+	Clock            clock.Clock
 	TemplateFilePath string
 	StylesPath       string
 	CommitHash       string
+	// FileNames of the pages are linked in the navigation.
+	FileNames map[Page]string
 }
 
 type HTMLRendererContent struct {
-	Menus         *[]restaurants.Menu
+	Title         string
+	Navigation    []Link
+	Days          []Day
 	StylesPath    string
 	CommitHash    string
 	ExecutionTime string
-	DayName       string
+	// Allergens are listed in the legend, which also filters out meals with
+	// the chosen allergens.
+	Allergens []restaurants.Allergen
 }
 
-func (r HTMLRenderer) RenderMenus(menus *[]restaurants.Menu) (*bytes.Buffer, error) {
+type Link struct {
+	Title  string
+	Href   string
+	Active bool
+}
+
+// Day holds the menus of all restaurants for one date, Heading is shown only on
+// pages with several days. Closed names the holiday or the weekend, on which all
+// restaurants are closed and no menus are shown.
+type Day struct {
+	Heading string
+	Closed  string
+	Menus   []restaurants.Menu
+}
+
+func (r HTMLRenderer) RenderMenus(weeks *[]restaurants.WeeklyMenu, page Page) (*bytes.Buffer, error) {
 	r.log("Loading HTML template from \"%s\"", r.TemplateFilePath)
 
-	temp, err := template.ParseFiles(r.TemplateFilePath)
+	temp, err := template.New(filepath.Base(r.TemplateFilePath)).Funcs(templateFuncs).ParseFiles(r.TemplateFilePath)
 	if err != nil {
 		r.err(err)
 		return nil, err
 	}
 
-	loc, err := time.LoadLocation("Europe/Bratislava")
-	if err != nil {
-		r.err(err)
-		return nil, err
-	}
+	currentTime := r.Clock.Now()
 
-	currentTime := time.Now().In(loc)
+	title, days := r.pageDays(weeks, page)
 
 	content := HTMLRendererContent{
-		Menus:         menus,
+		Title:         title,
+		Navigation:    r.navigation(page),
+		Days:          days,
 		StylesPath:    r.StylesPath,
 		CommitHash:    r.CommitHash,
 		ExecutionTime: currentTime.Format("15:04 2.1.2006"),
-		DayName:       r.DateResolver.SlovakWeekday(),
+		Allergens:     restaurants.Allergens,
 	}
 
-	r.log("Rendering HTML content")
+	r.log("Rendering HTML content of page \"%s\"", page)
 	renderBuff := new(bytes.Buffer)
 
 	err = temp.Execute(renderBuff, content)
@@ -87,6 +133,74 @@ func (r HTMLRenderer) RenderMenus(menus *[]restaurants.Menu) (*bytes.Buffer, err
 	return minifyBuff, nil
 }
 
+// pageDays picks the dates shown on the page.
+func (r HTMLRenderer) pageDays(weeks *[]restaurants.WeeklyMenu, page Page) (string, []Day) {
+	today := clock.Today(r.Clock)
+
+	switch page {
+	case PageTomorrow:
+		tomorrow := restaurants.NextWorkday(today)
+
+		return dayTitle(tomorrow), []Day{newDay(*weeks, tomorrow)}
+
+	case PageWeek:
+		dates := restaurants.WeekDates(today)
+		days := make([]Day, 0, len(dates))
+
+		for _, date := range dates {
+			day := newDay(*weeks, date)
+			day.Heading = dayTitle(date)
+
+			days = append(days, day)
+		}
+
+		title := fmt.Sprintf("%d. týždeň, %s - %s", clock.ISOWeek(today), clock.FormatDate(dates[0]), clock.FormatDate(dates[len(dates)-1]))
+
+		return title, days
+
+	default:
+		return dayTitle(today), []Day{newDay(*weeks, today)}
+	}
+}
+
+func (r HTMLRenderer) navigation(current Page) []Link {
+	links := make([]Link, 0, len(Pages))
+
+	for _, page := range Pages {
+		fileName, ok := r.FileNames[page]
+		if !ok {
+			continue
+		}
+
+		links = append(links, Link{
+			Title:  pageTitles[page],
+			Href:   fileName,
+			Active: page == current,
+		})
+	}
+
+	return links
+}
+
+func newDay(weeks []restaurants.WeeklyMenu, date time.Time) Day {
+	day := Day{Closed: clock.Holiday(date)}
+
+	if len(day.Closed) == 0 && clock.IsWeekend(date) {
+		day.Closed = "Víkend"
+	}
+
+	if len(day.Closed) == 0 {
+		day.Menus = restaurants.MenusForDate(weeks, date)
+	}
+
+	return day
+}
+
+// dayTitle formats the date like "Pondelok 15. apríla".
+func dayTitle(date time.Time) string {
+	return clock.DayName(date) + " " + clock.FormatDate(date)
+}
+
 func (r HTMLRenderer) GetErrorContent() *bytes.Buffer {
 	buff := new(bytes.Buffer)
 
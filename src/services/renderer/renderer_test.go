@@ -0,0 +1,108 @@
+package renderer
+
+import (
+	"menucko/restaurants"
+	"menucko/services/clock"
+	"testing"
+	"time"
+)
+
+func date(month time.Month, day int) time.Time {
+	return time.Date(2024, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// testWeeks has a restaurant with a menu on every day from 15.4.2024 to 5.5.2024
+// and one closed on 2.5.2024.
+func testWeeks() []restaurants.WeeklyMenu {
+	open := restaurants.WeeklyMenu{ID: "open", Name: "Open", Status: restaurants.StatusOK, Days: map[string]restaurants.Menu{}}
+	closed := restaurants.WeeklyMenu{ID: "closed", Name: "Closed", Status: restaurants.StatusOK, Days: map[string]restaurants.Menu{}}
+
+	for day := date(time.April, 15); day.Before(date(time.May, 6)); day = day.AddDate(0, 0, 1) {
+		open.Days[day.Format(restaurants.DateLayout)] = restaurants.Menu{
+			Status: restaurants.StatusOK,
+			Meals:  []restaurants.Meal{{Name: "Menu 1"}},
+		}
+	}
+
+	closed.Days["2024-05-02"] = restaurants.Menu{Status: restaurants.StatusClosed, Error: "closed according to the config"}
+
+	return []restaurants.WeeklyMenu{open, closed}
+}
+
+func TestPageDays(t *testing.T) {
+	tests := []struct {
+		name  string
+		today time.Time
+		page  Page
+		title string
+		// closed has the Closed reason of every shown day, an empty reason means
+		// the day shows the menus.
+		closed []string
+	}{
+		{"today", date(time.April, 16), PageToday, "Utorok 16. apríla", []string{""}},
+		{"today on a weekend", date(time.April, 20), PageToday, "Sobota 20. apríla", []string{"Víkend"}},
+		{"today on a holiday", date(time.May, 1), PageToday, "Streda 1. mája", []string{"Sviatok práce"}},
+		{"tomorrow", date(time.April, 16), PageTomorrow, "Streda 17. apríla", []string{""}},
+		{"tomorrow after a weekend", date(time.April, 19), PageTomorrow, "Pondelok 22. apríla", []string{""}},
+		{"tomorrow after a holiday", date(time.April, 30), PageTomorrow, "Štvrtok 2. mája", []string{""}},
+		{"week", date(time.April, 17), PageWeek, "16. týždeň, 15. apríla - 19. apríla", []string{"", "", "", "", ""}},
+		{"week on a weekend", date(time.April, 21), PageWeek, "16. týždeň, 15. apríla - 19. apríla", []string{"", "", "", "", ""}},
+		{"week with a holiday", date(time.April, 29), PageWeek, "18. týždeň, 29. apríla - 3. mája", []string{"", "", "Sviatok práce", "", ""}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			weeks := testWeeks()
+
+			renderer := HTMLRenderer{Clock: clock.DevClock{Frozen: test.today.Add(10 * time.Hour)}}
+
+			title, days := renderer.pageDays(&weeks, test.page)
+
+			if title != test.title {
+				t.Errorf("title = \"%s\", want \"%s\"", title, test.title)
+			}
+
+			if len(days) != len(test.closed) {
+				t.Fatalf("page has %d days, want %d", len(days), len(test.closed))
+			}
+
+			for index, day := range days {
+				if day.Closed != test.closed[index] {
+					t.Errorf("day %d is closed with \"%s\", want \"%s\"", index, day.Closed, test.closed[index])
+				}
+
+				if len(day.Closed) != 0 && len(day.Menus) != 0 {
+					t.Errorf("closed day %d has %d menus, want none", index, len(day.Menus))
+				}
+
+				if len(day.Closed) == 0 && len(day.Menus) != len(weeks) {
+					t.Errorf("day %d has %d menus, want %d", index, len(day.Menus), len(weeks))
+				}
+
+				if hasHeading := len(day.Heading) != 0; hasHeading != (test.page == PageWeek) {
+					t.Errorf("day %d has heading \"%s\" on page \"%s\"", index, day.Heading, test.page)
+				}
+			}
+		})
+	}
+}
+
+func TestNewDayMenus(t *testing.T) {
+	day := newDay(testWeeks(), date(time.May, 2))
+
+	if len(day.Menus) != 2 {
+		t.Fatalf("day has %d menus, want 2", len(day.Menus))
+	}
+
+	if menu := day.Menus[0]; menu.Status != restaurants.StatusOK || len(menu.Meals) != 1 {
+		t.Errorf("menu of the open restaurant = %+v", menu)
+	}
+
+	if menu := day.Menus[1]; menu.Status != restaurants.StatusClosed || menu.Name != "Closed" {
+		t.Errorf("menu of the closed restaurant = %+v", menu)
+	}
+
+	if menu := newDay(testWeeks(), date(time.May, 3)).Menus[1]; menu.Status != restaurants.StatusNotPublished {
+		t.Errorf("menu of a day the restaurant didn't publish has status \"%s\"", menu.Status)
+	}
+}
@@ -0,0 +1,195 @@
+package resultcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const entryExt = ".json"
+
+// Cache stores results derived from source bytes, e.g. the OCR text of an image, as
+// JSON files named by their key. The key addresses the content, so an entry never
+// goes stale. Entries unused for MaxAge are evicted, and the least recently used
+// ones are evicted once the directory grows over MaxSize bytes. Zero disables
+// the respective limit.
+type Cache struct {
+	Dir     string
+	MaxAge  time.Duration
+	MaxSize int64
+}
+
+// Key combines the SHA-256 of the source with a hash of the settings the result
+// depends on, e.g. the preprocessing pipeline.
+func Key(source []byte, settings ...string) string {
+	sourceHash := sha256.Sum256(source)
+	settingsHash := sha256.Sum256([]byte(strings.Join(settings, "\x00")))
+
+	return hex.EncodeToString(sourceHash[:]) + "-" + hex.EncodeToString(settingsHash[:8])
+}
+
+// Get unmarshals the entry into value and reports whether it was found.
+func (cache Cache) Get(key string, value any) (bool, error) {
+	path := cache.path(key)
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, err
+	}
+
+	if cache.MaxAge > 0 && time.Since(info.ModTime()) > cache.MaxAge {
+		return false, nil
+	}
+
+	if err = json.Unmarshal(content, value); err != nil {
+		return false, err
+	}
+
+	// The modification time tracks the last use for eviction.
+	now := time.Now()
+	_ = os.Chtimes(path, now, now)
+
+	return true, nil
+}
+
+// Put stores value under the key and evicts old entries afterwards.
+func (cache Cache) Put(key string, value any) error {
+	content, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(cache.Dir, os.ModePerm); err != nil {
+		return err
+	}
+
+	if err = WriteFileAtomic(cache.path(key), content); err != nil {
+		return err
+	}
+
+	return cache.Evict()
+}
+
+// Cached returns the value stored under the key, or computes and stores it. The
+// cache only saves work, so unreadable entries and failed writes are logged with
+// logf and the value is computed as if it wasn't cached.
+func Cached[T any](cache Cache, key string, logf func(format string, v ...any), compute func() (T, error)) (T, error) {
+	var value T
+
+	found, err := cache.Get(key, &value)
+	if err != nil {
+		logf("Ignoring unreadable cache entry \"%s\", Err: %v", key, err)
+	}
+
+	if found {
+		logf("Serving \"%s\" from cache", key)
+
+		return value, nil
+	}
+
+	value, err = compute()
+	if err != nil {
+		return value, err
+	}
+
+	if err = cache.Put(key, value); err != nil {
+		logf("Failed to cache \"%s\", Err: %v", key, err)
+	}
+
+	return value, nil
+}
+
+// Evict removes entries unused for MaxAge, then the least recently used entries
+// until the cache fits into MaxSize.
+func (cache Cache) Evict() error {
+	dirEntries, err := os.ReadDir(cache.Dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	var infos []os.FileInfo
+
+	for _, dirEntry := range dirEntries {
+		if dirEntry.IsDir() || filepath.Ext(dirEntry.Name()) != entryExt {
+			continue
+		}
+
+		info, err := dirEntry.Info()
+		if err != nil {
+			continue
+		}
+
+		infos = append(infos, info)
+	}
+
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].ModTime().After(infos[j].ModTime())
+	})
+
+	var size int64
+	var errs []error
+
+	for _, info := range infos {
+		size += info.Size()
+
+		expired := cache.MaxAge > 0 && time.Since(info.ModTime()) > cache.MaxAge
+		oversized := cache.MaxSize > 0 && size > cache.MaxSize
+
+		if !expired && !oversized {
+			continue
+		}
+
+		if err := os.Remove(filepath.Join(cache.Dir, info.Name())); err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, err)
+		}
+
+		size -= info.Size()
+	}
+
+	return errors.Join(errs...)
+}
+
+func (cache Cache) path(key string) string {
+	return filepath.Join(cache.Dir, key+entryExt)
+}
+
+// WriteFileAtomic writes into a temporary file first, so that concurrent runs never
+// read a partially written file.
+func WriteFileAtomic(path string, content []byte) error {
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	if _, err = file.Write(content); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return err
+	}
+
+	if err = file.Close(); err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+
+	return os.Rename(file.Name(), path)
+}
@@ -0,0 +1,132 @@
+package resultcache
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestKey(t *testing.T) {
+	key := Key([]byte("image"), "scale=4")
+
+	if key != Key([]byte("image"), "scale=4") {
+		t.Error("expected the same source and settings to give the same key")
+	}
+
+	if key == Key([]byte("image"), "scale=2") {
+		t.Error("expected different settings to give a different key")
+	}
+
+	if key == Key([]byte("other image"), "scale=4") {
+		t.Error("expected a different source to give a different key")
+	}
+
+	if Key(nil, "a", "bc") == Key(nil, "ab", "c") {
+		t.Error("expected settings boundaries to be part of the key")
+	}
+}
+
+func TestCacheGetPut(t *testing.T) {
+	cache := Cache{Dir: filepath.Join(t.TempDir(), "results")}
+
+	var value []string
+
+	found, err := cache.Get("missing", &value)
+	if err != nil || found {
+		t.Fatalf("expected a miss, got %v, %v", found, err)
+	}
+
+	if err = cache.Put("menu", []string{"Menu 1", "Menu 2"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	found, err = cache.Get("menu", &value)
+	if err != nil || !found {
+		t.Fatalf("expected a hit, got %v, %v", found, err)
+	}
+
+	if len(value) != 2 || value[1] != "Menu 2" {
+		t.Errorf("unexpected value %v", value)
+	}
+}
+
+func TestCached(t *testing.T) {
+	cache := Cache{Dir: t.TempDir()}
+	logf := func(format string, v ...any) {}
+	calls := 0
+
+	compute := func() (string, error) {
+		calls++
+
+		if calls == 1 {
+			return "", errors.New("OCR failed")
+		}
+
+		return "Menu 1", nil
+	}
+
+	if _, err := Cached(cache, "menu", logf, compute); err == nil {
+		t.Fatal("expected the error of the computation")
+	}
+
+	for i := 0; i < 2; i++ {
+		if value, err := Cached(cache, "menu", logf, compute); err != nil || value != "Menu 1" {
+			t.Fatalf("unexpected result %q, %v", value, err)
+		}
+	}
+
+	if calls != 2 {
+		t.Errorf("computed %d times, want a failure not to be cached and a success to be", calls)
+	}
+
+	if err := os.WriteFile(cache.path("menu"), []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if value, err := Cached(cache, "menu", logf, compute); err != nil || value != "Menu 1" || calls != 3 {
+		t.Errorf("expected an unreadable entry to be computed again, got %q, %v after %d calls", value, err, calls)
+	}
+}
+
+func TestCacheEvict(t *testing.T) {
+	cache := Cache{Dir: t.TempDir(), MaxAge: time.Hour}
+
+	for _, key := range []string{"old", "recent", "new"} {
+		if err := cache.Put(key, "0123456789"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	setAge := func(key string, age time.Duration) {
+		t.Helper()
+
+		at := time.Now().Add(-age)
+		if err := os.Chtimes(cache.path(key), at, at); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	setAge("old", 2*time.Hour)
+	setAge("recent", time.Minute)
+
+	var value string
+
+	if found, _ := cache.Get("old", &value); found {
+		t.Error("expected an expired entry to be ignored")
+	}
+
+	// Each entry takes 12 bytes, so only the most recently used one fits.
+	cache.MaxSize = 20
+
+	if err := cache.Evict(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for key, want := range map[string]bool{"old": false, "recent": false, "new": true} {
+		if _, err := os.Stat(cache.path(key)); (err == nil) != want {
+			t.Errorf("entry \"%s\" present: %v, want %v", key, err == nil, want)
+		}
+	}
+}